@@ -1,7 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	echomw "github.com/labstack/echo/v4/middleware"
@@ -10,21 +17,40 @@ import (
 	"pod_api/pkg/api"
 	openapi "pod_api/pkg/apigen/openapi"
 	"pod_api/pkg/clients/gigachat"
-	"pod_api/pkg/clients/openai"
 	"pod_api/pkg/config"
+	"pod_api/pkg/imaging"
 	"pod_api/pkg/logging"
 	"pod_api/pkg/metrics"
 	"pod_api/pkg/middleware"
+	"pod_api/pkg/providers"
+	batchrepo "pod_api/pkg/repository/batch"
 	imagerepo "pod_api/pkg/repository/image"
+	jobrepo "pod_api/pkg/repository/job"
+	sessionrepo "pod_api/pkg/repository/session"
+	"pod_api/pkg/webhook"
 )
 
+// shutdownTimeout bounds draining of in-flight requests on SIGINT/SIGTERM.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	// Setup logging
 	logging.Setup()
 
+	if err := run(); err != nil {
+		log.Fatal().Err(err).Msg("server failed")
+	}
+}
+
+// run wires the server and serves until a shutdown signal; deferred
+// cleanups run on every return.
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatal().Err(err).Msg("config failed")
+		return fmt.Errorf("config failed: %w", err)
 	}
 
 	// Observability pieces
@@ -34,44 +60,156 @@ func main() {
 	server.HideBanner = true
 	server.Use(echomw.Recover())
 	server.Use(middleware.RequestLogger(reg))
+	server.Use(middleware.AdminAuth("/api/v1/admin/", cfg.AdminToken))
 
 	// Healthcheck and metrics
 	server.GET("/ping", func(c echo.Context) error { return c.String(200, "pong") })
 	server.GET("/metrics", reg.EchoHandlerText)
 	server.GET("/metrics.json", reg.EchoHandlerJSON)
 
-	gigachatClient, err := gigachat.NewFromConfig(cfg)
+	processingOpts := imaging.NewOptions()
+	processingOpts.MaxDimension = cfg.ImageProcessing.MaxDimension
+	processingOpts.JPEGQuality = cfg.ImageProcessing.JPEGQuality
+	processingOpts.MaxPixels = cfg.ImageProcessing.MaxPixels
+	processingOpts.Metrics = reg
+	processor := imaging.NewProcessor(processingOpts)
+
+	var transcode providers.Transcoder
+	if cfg.ImageProcessing.Transcode {
+		transcode = processor.ToJPEG
+	}
+	registry, err := buildProviders(cfg, reg, transcode)
 	if err != nil {
-		log.Fatal().Err(err).Msg("gigachat client init failed")
+		return fmt.Errorf("providers init failed: %w", err)
 	}
+	textModel, err := registry.Text(cfg.Providers.Text...)
+	if err != nil {
+		return fmt.Errorf("text provider unavailable: %w", err)
+	}
+	imageModel, err := registry.Image(cfg.Providers.Vision...)
+	if err != nil {
+		return fmt.Errorf("vision provider unavailable: %w", err)
+	}
+	// Batches are optional: only some text providers process them.
+	batchModel, err := registry.Batch(cfg.Providers.Text...)
+	if err != nil {
+		log.Info().Err(err).Msg("batches disabled")
+	}
+	log.Info().
+		Strs("text", cfg.Providers.Text).
+		Strs("vision", cfg.Providers.Vision).
+		Strs("registered", registry.Names()).
+		Msg("providers ready")
 
-	openaiClient, err := openai.NewClient(
-		cfg.OpenAI.BasicKey,
-		cfg.OpenAI.URL,
-		cfg.OpenAI.Model,
-		cfg.OpenAI.RequestTimeout,
-	)
+	imageRepository, err := buildImageRepository(cfg, reg)
+	if err != nil {
+		return fmt.Errorf("image storage init failed: %w", err)
+	}
+	// Stores with background sweepers stop them on shutdown.
+	if closer, ok := imageRepository.(interface{ Close() }); ok {
+		defer closer.Close()
+	}
+	sessionRepository := sessionrepo.NewMemoryRepository(reg, cfg.Session.MaxMessages)
 
+	webhookOpts := webhook.NewOptions()
+	webhookOpts.Secret = []byte(cfg.Webhook.Secret)
+	webhookOpts.AllowedHosts = cfg.Webhook.AllowedHosts
+	webhookOpts.AllowPrivateNetworks = cfg.Webhook.AllowPrivateNetworks
+	webhookOpts.Workers = cfg.Webhook.Workers
+	webhookOpts.QueueSize = cfg.Webhook.QueueSize
+	webhookOpts.MaxAttempts = cfg.Webhook.MaxAttempts
+	webhookOpts.InitialBackoff = cfg.Webhook.InitialBackoff
+	webhookOpts.MaxBackoff = cfg.Webhook.MaxBackoff
+	webhookOpts.Timeout = cfg.Webhook.Timeout
+	webhookOpts.LogSize = cfg.Webhook.LogSize
+	webhookOpts.Metrics = reg
+	webhooks, err := webhook.NewDispatcher(webhookOpts)
 	if err != nil {
-		log.Fatal().Err(err).Msg("openai client init failed")
+		return fmt.Errorf("webhook dispatcher init failed: %w", err)
 	}
+	defer webhooks.Close()
+
+	handlerOpts := api.NewOptions()
+	handlerOpts.BaseURL = cfg.Server.BaseURL
+	handlerOpts.ImageTTL = cfg.ImageTTL
+	handlerOpts.ReadPolicy = api.ReadPolicy{Default: cfg.ImageReadPolicy, MaxReads: cfg.ImageMaxReads}
+	handlerOpts.URLSigning.TTL = cfg.ImageURL.TTL
+	handlerOpts.URLSigning.ModelAudience = cfg.ImageURL.ModelAudience
+	if cfg.ImageURL.SigningKey != "" {
+		handlerOpts.URLSigning.Keys = [][]byte{[]byte(cfg.ImageURL.SigningKey)}
+		for _, key := range cfg.ImageURL.PreviousKeys {
+			handlerOpts.URLSigning.Keys = append(handlerOpts.URLSigning.Keys, []byte(key))
+		}
+	}
+	handlerOpts.ImageDelivery = api.ImageDelivery(cfg.ImageDelivery)
+	handlerOpts.Upload = api.UploadLimits{
+		MaxImages:      cfg.ImageMaxCount,
+		MaxImageSize:   cfg.Upload.MaxImageSize,
+		MaxFieldSize:   cfg.Upload.MaxFieldSize,
+		MaxRequestSize: cfg.Upload.MaxRequestSize,
+	}
+	handlerOpts.SessionTTL = cfg.Session.TTL
+	handlerOpts.ModelTimeout = cfg.ModelRequestTimeout
+	handlerOpts.MaxOutputAttempts = cfg.ModelOutputMaxAttempts
+	handlerOpts.Generation = api.GenerationPolicy{
+		AllowedParams:       cfg.Generation.AllowedParams,
+		Models:              cfg.Generation.Models,
+		MaxTokens:           cfg.Generation.MaxTokens,
+		PositiveTemperature: cfg.UsesProvider(gigachat.ProviderName),
+	}
+	if cfg.ImageProcessing.Enabled {
+		handlerOpts.ImageProcessor = processor
+	}
+	if presigner, ok := imageRepository.(imagerepo.URLPresigner); ok && cfg.ImageStorage.S3.Presign {
+		handlerOpts.ImagePresigner = presigner
+	}
+	handlerOpts.JobRepository = jobrepo.NewMemoryRepository(reg)
+	handlerOpts.Jobs = api.JobOptions{Workers: cfg.Job.Workers, QueueSize: cfg.Job.QueueSize, TTL: cfg.Job.TTL}
+	if batchModel != nil {
+		handlerOpts.BatchModel = batchModel
+		handlerOpts.BatchRepository = batchrepo.NewMemoryRepository(reg)
+		handlerOpts.Batches = api.BatchOptions{MaxPrompts: cfg.Batch.MaxPrompts, PollInterval: cfg.Batch.PollInterval, TTL: cfg.Batch.TTL}
+	}
+	handlerOpts.Webhooks = webhooks
+	handlerOpts.Metrics = reg
+	handlerOpts.Providers = registry
 
-	imageRepository := imagerepo.NewMemoryRepository(reg)
 	handlers, err := api.NewHandlers(
-		gigachatClient,
-		openaiClient,
+		textModel,
+		imageModel,
 		imageRepository,
-		cfg.Server.BaseURL,
-		cfg.ImageTTL,
+		sessionRepository,
+		handlerOpts,
 	)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create handlers")
+		return fmt.Errorf("failed to create handlers: %w", err)
+	}
+	// Job workers stop on shutdown, before the webhooks they send.
+	handlers.Start(ctx)
+	defer handlers.Close()
+	if notifier, ok := imageRepository.(imagerepo.RemoveNotifier); ok {
+		notifier.OnRemove(handlers.ImageRemoved)
 	}
 	openapi.RegisterHandlers(server, openapi.NewStrictHandler(handlers, nil))
 
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	log.Info().Str("addr", addr).Msg("starting server")
-	if err := server.Start(addr); err != nil {
-		log.Fatal().Err(err).Msg("server failed")
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.Start(addr) }()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	log.Info().Msg("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server shutdown failed: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	return nil
 }
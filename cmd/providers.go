@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"pod_api/pkg/clients/fake"
+	"pod_api/pkg/clients/gigachat"
+	"pod_api/pkg/clients/openai"
+	"pod_api/pkg/config"
+	"pod_api/pkg/metrics"
+	"pod_api/pkg/providers"
+)
+
+// buildProviders initialises only the backends selected in config and
+// registers them. Unused backends are never contacted. transcode may be nil.
+func buildProviders(cfg config.Config, reg *metrics.Registry, transcode providers.Transcoder) (*providers.Registry, error) {
+	opts := providers.NewOptions()
+	opts.Breaker = providers.BreakerOptions{
+		FailureThreshold: cfg.Breaker.FailureThreshold,
+		ErrorRate:        cfg.Breaker.ErrorRate,
+		Window:           cfg.Breaker.Window,
+		MinCalls:         cfg.Breaker.MinCalls,
+		OpenTimeout:      cfg.Breaker.OpenTimeout,
+		HalfOpenProbes:   cfg.Breaker.HalfOpenProbes,
+	}
+	opts.Transcode = transcode
+	opts.Metrics = reg
+	registry := providers.NewRegistry(opts)
+
+	if cfg.UsesProvider(gigachat.ProviderName) {
+		client, err := gigachat.NewFromConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("gigachat client init failed: %w", err)
+		}
+		if err := registry.Register(client); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.UsesProvider(openai.ProviderName) {
+		client, err := openai.NewClient(
+			cfg.OpenAI.BasicKey,
+			cfg.OpenAI.URL,
+			cfg.OpenAI.Model,
+			cfg.OpenAI.RequestTimeout,
+			cfg.OpenAI.MaxTokens,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("openai client init failed: %w", err)
+		}
+		if err := registry.Register(client); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.UsesProvider(fake.ProviderName) {
+		if err := registry.Register(fake.NewClient()); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
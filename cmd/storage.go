@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"pod_api/pkg/config"
+	"pod_api/pkg/metrics"
+	imagerepo "pod_api/pkg/repository/image"
+)
+
+// buildImageRepository creates the image storage selected by IMAGE_STORAGE.
+func buildImageRepository(cfg config.Config, reg *metrics.Registry) (imagerepo.ImageRepository, error) {
+	switch cfg.ImageStorage.Backend {
+	case "filesystem":
+		opts := imagerepo.NewFilesystemOptions()
+		opts.SweepInterval = cfg.ImageStorage.SweepInterval
+		opts.Metrics = reg
+		repo, err := imagerepo.NewFilesystemRepository(cfg.ImageStorage.Dir, opts)
+		if err != nil {
+			return nil, fmt.Errorf("filesystem image storage: %w", err)
+		}
+		return repo, nil
+	case "s3":
+		opts := imagerepo.NewS3Options()
+		opts.Endpoint = cfg.ImageStorage.S3.Endpoint
+		opts.Region = cfg.ImageStorage.S3.Region
+		opts.Bucket = cfg.ImageStorage.S3.Bucket
+		opts.Prefix = cfg.ImageStorage.S3.Prefix
+		opts.AccessKeyID = cfg.ImageStorage.S3.AccessKeyID
+		opts.SecretAccessKey = cfg.ImageStorage.S3.SecretAccessKey
+		opts.PathStyle = cfg.ImageStorage.S3.PathStyle
+		opts.PresignTTL = cfg.ImageStorage.S3.PresignTTL
+		opts.SweepInterval = cfg.ImageStorage.SweepInterval
+		opts.Metrics = reg
+		repo, err := imagerepo.NewS3Repository(opts)
+		if err != nil {
+			return nil, fmt.Errorf("s3 image storage: %w", err)
+		}
+		return repo, nil
+	default:
+		return imagerepo.NewMemoryRepository(reg, imagerepo.MemoryLimits{
+			MaxBytes:   cfg.ImageStorage.MaxBytes,
+			MaxEntries: cfg.ImageStorage.MaxEntries,
+			Eviction:   imagerepo.Eviction(cfg.ImageStorage.Eviction),
+		}), nil
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/fashion"
+)
+
+// analyze parses the raw model answer into the public structured schema.
+func analyze(content string) *apigen.FashionAnalysis {
+	return toAPIAnalysis(fashion.Parse(content))
+}
+
+func toAPIAnalysis(result fashion.Result) *apigen.FashionAnalysis {
+	out := &apigen.FashionAnalysis{
+		Items:    make([]apigen.FashionItem, 0, len(result.Items)),
+		Problems: toAPIProblems(result.Problems),
+	}
+	if result.Refusal != "" {
+		out.Refusal = &result.Refusal
+	}
+	for _, item := range result.Items {
+		out.Items = append(out.Items, apigen.FashionItem{
+			Category:    string(item.Category),
+			Style:       string(item.Style),
+			Fit:         string(item.Fit),
+			Layer:       string(item.Layer),
+			Formality:   string(item.Formality),
+			Gender:      string(item.Gender),
+			Season:      string(item.Season),
+			Temperature: string(item.Temperature),
+			Colors:      toStrings(item.Colors),
+			Materials:   toStrings(item.Materials),
+		})
+	}
+	return out
+}
+
+func toAPIProblems(problems []fashion.Problem) []apigen.ValidationProblem {
+	out := make([]apigen.ValidationProblem, 0, len(problems))
+	for _, p := range problems {
+		problem := apigen.ValidationProblem{Message: p.Message}
+		if p.Item >= 0 {
+			index := p.Item
+			problem.Item = &index
+		}
+		if p.Field != "" {
+			field := p.Field
+			problem.Field = &field
+		}
+		out = append(out, problem)
+	}
+	return out
+}
+
+func toStrings[T ~string](values []T) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, string(v))
+	}
+	return out
+}
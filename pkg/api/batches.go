@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/models"
+	batchrepo "pod_api/pkg/repository/batch"
+)
+
+// Rejections of POST /api/v1/batches.
+const (
+	errorBatchSubmitFailed = "batch_submit_failed"
+	errorBatchesDisabled   = "batches_disabled"
+)
+
+// maxBatchPollFailures is how many polls in a row may fail before the batch
+// is marked failed, e.g. when its results can no longer be downloaded.
+const maxBatchPollFailures = 5
+
+// BatchOptions configures batch analysis.
+type BatchOptions struct {
+	// MaxPrompts caps prompts per batch.
+	MaxPrompts int
+	// PollInterval is how often the provider is asked for batch progress.
+	PollInterval time.Duration
+	// TTL is how long a batch is kept after its last update.
+	TTL time.Duration
+}
+
+// CreateBatch handles POST /api/v1/batches
+func (h *Handlers) CreateBatch(ctx context.Context, request apigen.CreateBatchRequestObject) (apigen.CreateBatchResponseObject, error) {
+	if request.Body == nil {
+		return apigen.CreateBatch400JSONResponse{Error: "bad_request"}, nil
+	}
+	if h.batchModel == nil || h.batchRepository == nil {
+		return apigen.CreateBatch503JSONResponse{Error: errorBatchesDisabled}, nil
+	}
+	prompts := request.Body.Prompts
+	if len(prompts) == 0 || len(prompts) > h.batches.MaxPrompts {
+		details := map[string]interface{}{"field": "prompts", "limit": h.batches.MaxPrompts}
+		return apigen.CreateBatch400JSONResponse{Error: "bad_request", Details: &details}, nil
+	}
+	requests := make([]models.ChatRequest, 0, len(prompts))
+	items := make([]batchrepo.Item, 0, len(prompts))
+	for i, prompt := range prompts {
+		if strings.TrimSpace(prompt) == "" {
+			details := map[string]interface{}{"field": "prompts", "index": i}
+			return apigen.CreateBatch400JSONResponse{Error: "bad_request", Details: &details}, nil
+		}
+		requests = append(requests, models.NewTextRequest(prompt))
+		items = append(items, batchrepo.Item{Prompt: prompt})
+	}
+
+	// The batch is recorded before it is submitted, so nothing is left
+	// running at the provider without a record here.
+	batch, err := h.batchRepository.Create(ctx, batchrepo.Batch{
+		Status: batchrepo.StatusCreated,
+		Items:  items,
+	}, h.batches.TTL)
+	if err != nil {
+		return nil, err
+	}
+	submitted, err := h.batchModel.CreateBatch(ctx, requests)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("batch_id", batch.ID).Int("prompts", len(prompts)).Msg("batch not submitted")
+		h.countBatch(ctx, "rejected")
+		batch.Status = batchrepo.StatusFailed
+		batch.Error = "batch not submitted"
+		if _, err := h.batchRepository.Update(ctx, batch); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("batch_id", batch.ID).Msg("batch failure not stored")
+		}
+		return apigen.CreateBatch502JSONResponse{Error: errorBatchSubmitFailed}, nil
+	}
+	batch.ProviderID = submitted.ID
+	if batch, err = h.batchRepository.Update(ctx, batch); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("provider_batch_id", submitted.ID).Msg("submitted batch not stored")
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Str("batch_id", batch.ID).Str("provider_batch_id", submitted.ID).Int("prompts", len(prompts)).Msg("batch submitted")
+	pollCtx, cancel := h.detach(ctx)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer cancel()
+		h.pollBatch(pollCtx, batch)
+	}()
+
+	return apigen.CreateBatch202JSONResponse{
+		Body:    toAPIBatch(batch),
+		Headers: apigen.CreateBatch202ResponseHeaders{Location: h.baseURL + "/api/v1/batches/" + batch.ID},
+	}, nil
+}
+
+// GetBatch handles GET /api/v1/batches/{id}
+func (h *Handlers) GetBatch(ctx context.Context, request apigen.GetBatchRequestObject) (apigen.GetBatchResponseObject, error) {
+	if h.batchRepository == nil {
+		return apigen.GetBatch404JSONResponse{Error: "not_found"}, nil
+	}
+	batch, ok := h.batchRepository.Get(ctx, request.Id.String())
+	if !ok {
+		return apigen.GetBatch404JSONResponse{Error: "not_found"}, nil
+	}
+	return apigen.GetBatch200JSONResponse(toAPIBatch(batch)), nil
+}
+
+// pollBatch follows a submitted batch until it finishes, expires here or
+// the handlers are closed. Failed polls are retried on the next tick; after
+// maxBatchPollFailures in a row the batch is marked failed.
+func (h *Handlers) pollBatch(ctx context.Context, batch batchrepo.Batch) {
+	ticker := time.NewTicker(h.batches.PollInterval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Info().Str("batch_id", batch.ID).Msg("batch polling stopped")
+			return
+		case <-ticker.C:
+		}
+		if _, ok := h.batchRepository.Get(ctx, batch.ID); !ok {
+			log.Ctx(ctx).Warn().Str("batch_id", batch.ID).Msg("batch expired before it finished")
+			return
+		}
+		next, err := h.refreshBatch(ctx, batch)
+		if err != nil {
+			failures++
+			log.Ctx(ctx).Warn().Err(err).Str("batch_id", batch.ID).Int("failures", failures).Msg("batch poll failed")
+			if failures < maxBatchPollFailures || ctx.Err() != nil {
+				continue
+			}
+			batch.Status = batchrepo.StatusFailed
+			batch.Error = fmt.Sprintf("batch progress unavailable after %d attempts: %v", failures, err)
+			if next, err = h.batchRepository.Update(ctx, batch); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("batch_id", batch.ID).Msg("batch failure not stored")
+				return
+			}
+		}
+		failures = 0
+		batch = next
+		if batch.Done() {
+			h.countBatch(ctx, string(batch.Status))
+			log.Ctx(ctx).Info().Str("batch_id", batch.ID).Str("status", string(batch.Status)).
+				Int("completed", batch.Completed).Int("failed", batch.Failed).Msg("batch finished")
+			return
+		}
+	}
+}
+
+// refreshBatch stores the provider's progress and, once the batch is
+// completed, its answers. The update also prolongs the batch TTL.
+func (h *Handlers) refreshBatch(ctx context.Context, batch batchrepo.Batch) (batchrepo.Batch, error) {
+	upstream, err := h.batchModel.GetBatch(ctx, batch.ProviderID)
+	if err != nil {
+		return batch, err
+	}
+	batch.Completed, batch.Failed = upstream.Completed, upstream.Failed
+	switch upstream.Status {
+	case models.BatchCreated:
+		batch.Status = batchrepo.StatusCreated
+	case models.BatchInProgress:
+		batch.Status = batchrepo.StatusInProgress
+	case models.BatchCompleted:
+		results, err := h.batchModel.BatchResults(ctx, upstream)
+		if err != nil {
+			return batch, err
+		}
+		batch.Status = batchrepo.StatusCompleted
+		batch.Items = batchItems(batch.Items, results)
+		batch.Completed, batch.Failed = 0, 0
+		for _, item := range batch.Items {
+			if item.Error != "" {
+				batch.Failed++
+			} else {
+				batch.Completed++
+			}
+		}
+	default:
+		batch.Status = batchrepo.StatusFailed
+		batch.Error = fmt.Sprintf("provider reported batch status %q", upstream.Status)
+	}
+	return h.batchRepository.Update(ctx, batch)
+}
+
+// batchItems returns a copy of items with the answers filled in by request
+// index; prompts left without a result are marked failed.
+func batchItems(items []batchrepo.Item, results []models.BatchResult) []batchrepo.Item {
+	out := make([]batchrepo.Item, len(items))
+	for i, item := range items {
+		out[i] = batchrepo.Item{Prompt: item.Prompt, Error: "no result"}
+	}
+	for _, result := range results {
+		if result.Index < 0 || result.Index >= len(out) {
+			continue
+		}
+		item := &out[result.Index]
+		switch {
+		case result.Response != nil && len(result.Response.Choices) > 0:
+			item.Content, item.Error = result.Response.Choices[0].Message.Content, ""
+		case result.Error != "":
+			item.Error = result.Error
+		default:
+			item.Error = "empty answer"
+		}
+	}
+	return out
+}
+
+func (h *Handlers) countBatch(ctx context.Context, status string) {
+	if h.reg != nil {
+		h.reg.Inc(ctx, "batches_total", map[string]string{"status": status}, 1)
+	}
+}
+
+func toAPIBatch(batch batchrepo.Batch) apigen.Batch {
+	id, _ := uuid.Parse(batch.ID)
+	out := apigen.Batch{
+		Id:        id,
+		Status:    apigen.BatchStatus(batch.Status),
+		Total:     len(batch.Items),
+		Completed: batch.Completed,
+		Failed:    batch.Failed,
+		Items:     make([]apigen.BatchItem, 0, len(batch.Items)),
+		CreatedAt: batch.CreatedAt,
+		UpdatedAt: batch.UpdatedAt,
+	}
+	if batch.Error != "" {
+		batchError := batch.Error
+		out.Error = &batchError
+	}
+	if !batch.ExpiresAt.IsZero() {
+		expiresAt := batch.ExpiresAt
+		out.ExpiresAt = &expiresAt
+	}
+	for i, item := range batch.Items {
+		apiItem := apigen.BatchItem{Index: i, Prompt: item.Prompt}
+		if item.Content != "" {
+			content := item.Content
+			apiItem.Description = &content
+			apiItem.Analysis = analyze(content)
+		}
+		if item.Error != "" {
+			itemError := item.Error
+			apiItem.Error = &itemError
+		}
+		out.Items = append(out.Items, apiItem)
+	}
+	return out
+}
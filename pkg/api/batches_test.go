@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/models"
+	batchrepo "pod_api/pkg/repository/batch"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stubBatches completes a batch on the second status check, or never with
+// pending set. With submitErr set, batches are rejected; with resultsErr
+// set, their results cannot be downloaded.
+type stubBatches struct {
+	requests   []models.ChatRequest
+	pending    bool
+	submitErr  error
+	resultsErr error
+
+	mu      sync.Mutex
+	checks  int
+	fetches int
+}
+
+func (s *stubBatches) CreateBatch(_ context.Context, requests []models.ChatRequest) (models.Batch, error) {
+	if s.submitErr != nil {
+		return models.Batch{}, s.submitErr
+	}
+	s.requests = requests
+	return models.Batch{ID: "upstream", Status: models.BatchCreated, Total: len(requests)}, nil
+}
+
+func (s *stubBatches) GetBatch(_ context.Context, id string) (models.Batch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks++
+	if id != "upstream" {
+		return models.Batch{}, errors.New("unknown batch")
+	}
+	if s.checks < 2 || s.pending {
+		return models.Batch{ID: id, Status: models.BatchInProgress, Total: len(s.requests), Completed: 1}, nil
+	}
+	return models.Batch{ID: id, Status: models.BatchCompleted, Total: len(s.requests), OutputFileID: "out"}, nil
+}
+
+func (s *stubBatches) BatchResults(context.Context, models.Batch) ([]models.BatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	if s.resultsErr != nil {
+		return nil, s.resultsErr
+	}
+	valid := "[" + strings.TrimSuffix(strings.Repeat(validItem+",", 5), ",") + "]"
+	return []models.BatchResult{
+		{Index: 1, Error: "blacklist"},
+		{Index: 0, Response: answer(valid)},
+	}, nil
+}
+
+func newBatchHandlers(stub *stubBatches) *Handlers {
+	return &Handlers{
+		batchModel:      stub,
+		batchRepository: batchrepo.NewMemoryRepository(nil),
+		batches:         BatchOptions{MaxPrompts: 2, PollInterval: time.Millisecond, TTL: time.Minute},
+	}
+}
+
+func createBatch(t *testing.T, h *Handlers) apigen.Batch {
+	t.Helper()
+	response, err := h.CreateBatch(context.Background(), apigen.CreateBatchRequestObject{Body: &apigen.BatchRequest{Prompts: []string{"пальто", "джинсы"}}})
+	require.NoError(t, err)
+	return response.(apigen.CreateBatch202JSONResponse).Body
+}
+
+func TestBatches(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubBatches{}
+	h := newBatchHandlers(stub)
+	h.Start(ctx)
+	defer h.Close()
+
+	for _, prompts := range [][]string{nil, {"a", "b", "c"}, {"пальто", " "}} {
+		response, err := h.CreateBatch(ctx, apigen.CreateBatchRequestObject{Body: &apigen.BatchRequest{Prompts: prompts}})
+		require.NoError(t, err)
+		require.IsType(t, apigen.CreateBatch400JSONResponse{}, response, prompts)
+	}
+
+	response, err := h.CreateBatch(ctx, apigen.CreateBatchRequestObject{Body: &apigen.BatchRequest{Prompts: []string{"пальто", "джинсы"}}})
+	require.NoError(t, err)
+	created := response.(apigen.CreateBatch202JSONResponse)
+	require.Equal(t, apigen.BatchStatusCreated, created.Body.Status)
+	require.Equal(t, "/api/v1/batches/"+created.Body.Id.String(), created.Headers.Location)
+	require.Len(t, stub.requests, 2)
+
+	var batch apigen.Batch
+	require.Eventually(t, func() bool {
+		got, err := h.GetBatch(ctx, apigen.GetBatchRequestObject{Id: created.Body.Id})
+		require.NoError(t, err)
+		batch = apigen.Batch(got.(apigen.GetBatch200JSONResponse))
+		return batch.Status == apigen.BatchStatusCompleted
+	}, time.Second, time.Millisecond)
+
+	require.Equal(t, 2, batch.Total)
+	require.Equal(t, 1, batch.Completed)
+	require.Equal(t, 1, batch.Failed)
+	require.Equal(t, "пальто", batch.Items[0].Prompt)
+	require.NotNil(t, batch.Items[0].Analysis)
+	require.Len(t, batch.Items[0].Analysis.Items, 5)
+	require.Nil(t, batch.Items[0].Error)
+	require.Equal(t, "blacklist", *batch.Items[1].Error)
+	require.Nil(t, batch.Items[1].Description)
+}
+
+func TestBatchResultsUnavailable(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubBatches{resultsErr: errors.New("output file is gone")}
+	h := newBatchHandlers(stub)
+	h.Start(ctx)
+	defer h.Close()
+
+	created := createBatch(t, h)
+	var batch apigen.Batch
+	require.Eventually(t, func() bool {
+		got, err := h.GetBatch(ctx, apigen.GetBatchRequestObject{Id: created.Id})
+		require.NoError(t, err)
+		batch = apigen.Batch(got.(apigen.GetBatch200JSONResponse))
+		return batch.Status == apigen.BatchStatusFailed
+	}, time.Second, time.Millisecond)
+	require.Contains(t, *batch.Error, "output file is gone")
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	require.Equal(t, maxBatchPollFailures, stub.fetches)
+}
+
+func TestCloseStopsBatchPolling(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubBatches{pending: true}
+	h := newBatchHandlers(stub)
+	h.Start(ctx)
+
+	created := createBatch(t, h)
+	require.Eventually(t, func() bool {
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+		return stub.checks > 1
+	}, time.Second, time.Millisecond)
+	h.Close()
+
+	stub.mu.Lock()
+	checks := stub.checks
+	stub.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	require.Equal(t, checks, stub.checks, "no polls after Close")
+	got, err := h.GetBatch(ctx, apigen.GetBatchRequestObject{Id: created.Id})
+	require.NoError(t, err)
+	require.Equal(t, apigen.BatchStatusInProgress, got.(apigen.GetBatch200JSONResponse).Status)
+}
+
+func TestCreateBatchSubmitFailed(t *testing.T) {
+	h := newBatchHandlers(&stubBatches{submitErr: errors.New("quota exceeded")})
+	response, err := h.CreateBatch(context.Background(), apigen.CreateBatchRequestObject{Body: &apigen.BatchRequest{Prompts: []string{"пальто"}}})
+	require.NoError(t, err)
+	require.Equal(t, apigen.CreateBatch502JSONResponse{Error: errorBatchSubmitFailed}, response)
+}
+
+func TestCreateBatchDisabled(t *testing.T) {
+	h := &Handlers{}
+	response, err := h.CreateBatch(context.Background(), apigen.CreateBatchRequestObject{Body: &apigen.BatchRequest{Prompts: []string{"пальто"}}})
+	require.NoError(t, err)
+	require.Equal(t, apigen.CreateBatch503JSONResponse{Error: errorBatchesDisabled}, response)
+}
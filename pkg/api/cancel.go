@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/providers"
+)
+
+// Error codes for model calls that did not complete.
+const (
+	errorClientClosed      = "client_closed_request"
+	errorModelTimeout      = "model_timeout"
+	errorModelNotSupported = "model_not_supported"
+	errorImageNotSupported = "image_type_not_supported"
+	errorModel             = "model_error"
+)
+
+// modelContext bounds a model call by the configured deadline. The request
+// context is the parent, so a client disconnect cancels the call as well.
+func (h *Handlers) modelContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if h.modelTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, h.modelTimeout)
+}
+
+// classifyModelError tells apart a client abort, an expired model deadline,
+// an unsupported model selection and a model failure. ctx is the context
+// the model was called with.
+func (h *Handlers) classifyModelError(ctx context.Context, flow string, err error) string {
+	code := errorModel
+	switch {
+	case errors.Is(err, providers.ErrModelNotSupported):
+		return errorModelNotSupported
+	case errors.Is(err, providers.ErrImageTypeNotSupported):
+		return errorImageNotSupported
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		code = errorClientClosed
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		code = errorModelTimeout
+	}
+
+	log.Ctx(ctx).Error().Err(err).Str("flow", flow).Str("reason", code).Msg("model call failed")
+	if h.reg != nil && code != errorModel {
+		h.reg.Inc(context.WithoutCancel(ctx), "model_requests_aborted_total", map[string]string{"flow": flow, "reason": code}, 1)
+	}
+	return code
+}
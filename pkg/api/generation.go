@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/models"
+)
+
+// Generation option names, as in GENERATION_ALLOWED_PARAMS.
+const (
+	paramModel             = "model"
+	paramTemperature       = "temperature"
+	paramTopP              = "top_p"
+	paramMaxTokens         = "max_tokens"
+	paramRepetitionPenalty = "repetition_penalty"
+)
+
+// GenerationPolicy limits the generation options clients may request.
+type GenerationPolicy struct {
+	// AllowedParams lists options clients may set; others are rejected.
+	AllowedParams []string
+	// Models maps models clients may select to their max_tokens limit.
+	Models map[string]int
+	// MaxTokens caps max_tokens for requests that do not select a model.
+	MaxTokens int
+	// PositiveTemperature rejects temperature 0 for providers that do not
+	// accept it (GigaChat).
+	PositiveTemperature bool
+}
+
+// GenerationError is returned for a rejected generation option.
+type GenerationError struct {
+	Param   string
+	Message string
+}
+
+func (e *GenerationError) Error() string {
+	return fmt.Sprintf("invalid generation option %s: %s", e.Param, e.Message)
+}
+
+func (e *GenerationError) response() apigen.ErrorResponse {
+	return apigen.ErrorResponse{
+		Error:   "invalid_generation_options",
+		Details: &map[string]interface{}{"param": e.Param, "message": e.Message},
+	}
+}
+
+// resolve validates requested options against the policy and converts them
+// for the model clients. nil options keep provider defaults.
+func (p GenerationPolicy) resolve(options *apigen.GenerationOptions) (models.GenerationOptions, error) {
+	var out models.GenerationOptions
+	if options == nil {
+		return out, nil
+	}
+
+	maxTokensLimit := p.MaxTokens
+	if options.Model != nil {
+		if err := p.allow(paramModel); err != nil {
+			return out, err
+		}
+		limit, ok := p.Models[*options.Model]
+		if !ok {
+			return out, &GenerationError{Param: paramModel, Message: fmt.Sprintf("model %q is not available", *options.Model)}
+		}
+		out.Model = *options.Model
+		maxTokensLimit = limit
+	}
+
+	if options.Temperature != nil {
+		if err := p.allowRange(paramTemperature, *options.Temperature, 0, 2); err != nil {
+			return out, err
+		}
+		if p.PositiveTemperature && *options.Temperature == 0 {
+			return out, &GenerationError{Param: paramTemperature, Message: "should be greater than 0"}
+		}
+		out.Temperature = options.Temperature
+	}
+	if options.TopP != nil {
+		if err := p.allowRange(paramTopP, *options.TopP, 0, 1); err != nil {
+			return out, err
+		}
+		out.TopP = options.TopP
+	}
+	if options.RepetitionPenalty != nil {
+		if err := p.allowRange(paramRepetitionPenalty, *options.RepetitionPenalty, 0, 2); err != nil {
+			return out, err
+		}
+		if *options.RepetitionPenalty == 0 {
+			return out, &GenerationError{Param: paramRepetitionPenalty, Message: "should be greater than 0"}
+		}
+		out.RepetitionPenalty = options.RepetitionPenalty
+	}
+	if options.MaxTokens != nil {
+		if err := p.allow(paramMaxTokens); err != nil {
+			return out, err
+		}
+		if *options.MaxTokens < 1 || *options.MaxTokens > maxTokensLimit {
+			return out, &GenerationError{Param: paramMaxTokens, Message: fmt.Sprintf("should be between 1 and %d", maxTokensLimit)}
+		}
+		out.MaxTokens = options.MaxTokens
+	}
+
+	return out, nil
+}
+
+func (p GenerationPolicy) allow(param string) error {
+	if !slices.Contains(p.AllowedParams, param) {
+		return &GenerationError{Param: param, Message: "option is not allowed"}
+	}
+	return nil
+}
+
+func (p GenerationPolicy) allowRange(param string, v, lo, hi float64) error {
+	if err := p.allow(param); err != nil {
+		return err
+	}
+	if v < lo || v > hi {
+		return &GenerationError{Param: param, Message: fmt.Sprintf("should be between %g and %g", lo, hi)}
+	}
+	return nil
+}
+
+// parseGenerationOptions decodes options sent as a JSON form field.
+func parseGenerationOptions(raw string) (*apigen.GenerationOptions, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.DisallowUnknownFields()
+	var options apigen.GenerationOptions
+	if err := decoder.Decode(&options); err != nil {
+		return nil, &GenerationError{Param: "options", Message: err.Error()}
+	}
+	return &options, nil
+}
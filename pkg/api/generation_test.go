@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	apigen "pod_api/pkg/apigen/openapi"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerationPolicyResolve(t *testing.T) {
+	policy := GenerationPolicy{
+		AllowedParams: []string{paramModel, paramTemperature, paramMaxTokens},
+		Models:        map[string]int{"GigaChat-2-Max": 8192},
+		MaxTokens:     1024,
+	}
+
+	model, temperature, maxTokens := "GigaChat-2-Max", 0.3, 4096
+	options, err := policy.resolve(&apigen.GenerationOptions{Model: &model, Temperature: &temperature, MaxTokens: &maxTokens})
+	require.NoError(t, err)
+	require.Equal(t, model, options.Model)
+	require.Equal(t, 4096, *options.MaxTokens)
+
+	cases := map[string]apigen.GenerationOptions{
+		"max_tokens above default limit": {MaxTokens: &maxTokens},
+		"top_p not allowed":              {TopP: &temperature},
+		"unknown model":                  {Model: ptr("gpt-4o")},
+		"temperature out of range":       {Temperature: ptr(2.5)},
+	}
+	for name, options := range cases {
+		_, err := policy.resolve(&options)
+		var invalid *GenerationError
+		require.True(t, errors.As(err, &invalid), name)
+		require.Equal(t, "invalid_generation_options", invalid.response().Error)
+	}
+
+	zero := apigen.GenerationOptions{Temperature: ptr(0.0)}
+	_, err = policy.resolve(&zero)
+	require.NoError(t, err, "temperature 0 is valid for providers that accept it")
+	policy.PositiveTemperature = true
+	_, err = policy.resolve(&zero)
+	var invalid *GenerationError
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, paramTemperature, invalid.Param)
+	_, err = policy.resolve(&apigen.GenerationOptions{Temperature: ptr(0.01)})
+	require.NoError(t, err)
+
+	_, err = parseGenerationOptions(`{"temperature":0.5,"seed":1}`)
+	require.Error(t, err)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -8,36 +8,182 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/imaging"
+	"pod_api/pkg/metrics"
+	"pod_api/pkg/middleware"
 	"pod_api/pkg/models"
+	"pod_api/pkg/providers"
+	batchrepo "pod_api/pkg/repository/batch"
 	imagerepo "pod_api/pkg/repository/image"
+	jobrepo "pod_api/pkg/repository/job"
+	sessionrepo "pod_api/pkg/repository/session"
+	"pod_api/pkg/webhook"
 )
 
-type TextModel interface {
-	// SendMessage sends user text to the model and returns a unified
-	// chat response compatible with GigaChat/OpenAI along with an error.
-	SendMessage(message string) (*models.ChatResponse, error)
-}
+// Model interfaces are defined by the providers package; aliases keep the
+// handler signatures short.
+type (
+	TextModel          = providers.TextModel
+	StreamingTextModel = providers.StreamingTextModel
+	ImageModel         = providers.ImageModel
+	BatchModel         = providers.BatchModel
+)
+
+// ImageDelivery selects how uploaded images reach vision models.
+type ImageDelivery string
+
+const (
+	// ImageDeliveryAuto delivers by link when BaseURL or ImagePresigner is
+	// set and inline otherwise.
+	ImageDeliveryAuto ImageDelivery = "auto"
+	// ImageDeliveryURL passes a link to the stored image; the provider
+	// must be able to reach BaseURL, or the storage when ImagePresigner is set.
+	ImageDeliveryURL ImageDelivery = "url"
+	// ImageDeliveryInline embeds the image bytes into the model request.
+	ImageDeliveryInline ImageDelivery = "inline"
+)
+
+// errorStorageFull is returned with 503 when the image repository has no
+// room for uploads.
+const errorStorageFull = "storage_full"
 
-type ImageModel interface {
-	SendImage(message string, url string) (*models.ChatResponse, error)
+// ImageProcessor normalises uploaded images before they are stored
+// (implemented by imaging.Processor).
+type ImageProcessor interface {
+	Process(ctx context.Context, image models.Image) (models.Image, error)
 }
 
 // Handlers implements apigen.StrictServerInterface.
 type Handlers struct {
-	text            TextModel
-	image           ImageModel
-	imageRepository imagerepo.ImageRepository
-	baseURL         string
-	imageTTL        time.Duration
+	text              TextModel
+	image             ImageModel
+	imageRepository   imagerepo.ImageRepository
+	sessionRepository sessionrepo.SessionRepository
+	jobRepository     jobrepo.JobRepository
+	jobQueue          chan imageJob
+	jobWorkers        int
+	jobTTL            time.Duration
+	batchModel        BatchModel
+	batchRepository   batchrepo.BatchRepository
+	batches           BatchOptions
+	imageProcessor    ImageProcessor
+	imagePresigner    imagerepo.URLPresigner
+	providers         ProviderStatusSource
+	webhooks          WebhookSender
+	reg               *metrics.Registry
+	baseURL           string
+	imageDelivery     ImageDelivery
+	imageTTL          time.Duration
+	readPolicy        ReadPolicy
+	urlSigning        ImageURLSigning
+	upload            UploadLimits
+	sessionTTL        time.Duration
+	modelTimeout      time.Duration
+	maxOutputAttempts int
+	generation        GenerationPolicy
+
+	// background is cancelled by Close; job workers and batch pollers run
+	// under it and wg tracks them.
+	background context.Context
+	stop       context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// Options controls optional parameters for NewHandlers.
+type Options struct {
+	// BaseURL is prepended to image links; relative links are used when empty.
+	BaseURL    string
+	ImageTTL   time.Duration
+	SessionTTL time.Duration
+
+	// ReadPolicy limits downloads of uploaded images.
+	ReadPolicy ReadPolicy
+
+	// URLSigning signs image links; without keys links are bare ids.
+	URLSigning ImageURLSigning
+
+	// Upload bounds multipart ingestion of POST /api/v1/chat/image.
+	Upload UploadLimits
+
+	// ImageDelivery selects how images reach vision models; see ImageDeliveryAuto.
+	ImageDelivery ImageDelivery
+
+	// ModelTimeout bounds the model work of a single request, including
+	// repair attempts and fallbacks (0 — no deadline besides the client's).
+	ModelTimeout time.Duration
+
+	// MaxOutputAttempts limits model calls per request when the answer
+	// violates the output contract (1 disables repair).
+	MaxOutputAttempts int
+
+	// Generation limits per-request generation options.
+	Generation GenerationPolicy
+
+	// ImageProcessor is optional; nil stores and forwards uploads as is.
+	ImageProcessor ImageProcessor
+
+	// ImagePresigner is optional; when set, vision models get presigned
+	// storage links instead of /api/v1/images/{id} links.
+	ImagePresigner imagerepo.URLPresigner
+
+	// Providers is optional; it feeds GET /api/v1/providers.
+	Providers ProviderStatusSource
+
+	// JobRepository is optional; nil disables /api/v1/jobs.
+	JobRepository jobrepo.JobRepository
+
+	// Jobs sizes the worker pool of /api/v1/jobs.
+	Jobs JobOptions
+
+	// BatchModel and BatchRepository are optional; unless both are set
+	// /api/v1/batches answers 503.
+	BatchModel      BatchModel
+	BatchRepository batchrepo.BatchRepository
+
+	// Batches limits and polls /api/v1/batches.
+	Batches BatchOptions
+
+	// Webhooks is optional; nil rejects callback URLs.
+	Webhooks WebhookSender
+
+	// Metrics is optional; nil disables handler metrics.
+	Metrics *metrics.Registry
+}
+
+// NewOptions returns sensible defaults.
+func NewOptions() Options {
+	return Options{
+		ImageTTL:          30 * time.Second,
+		ImageDelivery:     ImageDeliveryAuto,
+		ReadPolicy:        ReadPolicy{Default: readPolicySingle, MaxReads: 10},
+		URLSigning:        ImageURLSigning{TTL: 10 * time.Minute, ModelAudience: "openai"},
+		SessionTTL:        30 * time.Minute,
+		Jobs:              JobOptions{Workers: 4, QueueSize: 64, TTL: time.Hour},
+		Batches:           BatchOptions{MaxPrompts: 1000, PollInterval: 30 * time.Second, TTL: 24 * time.Hour},
+		ModelTimeout:      2 * time.Minute,
+		MaxOutputAttempts: 3,
+		Generation: GenerationPolicy{
+			AllowedParams: []string{paramTemperature, paramTopP, paramMaxTokens, paramRepetitionPenalty},
+			MaxTokens:     4096,
+		},
+		Upload: UploadLimits{
+			MaxImages:      4,
+			MaxImageSize:   10 << 20,
+			MaxFieldSize:   64 << 10,
+			MaxRequestSize: 20 << 20,
+		},
+	}
 }
 
 // NewHandlers constructs Handlers with provided models and dependencies.
-func NewHandlers(text TextModel, image ImageModel, imageRepository imagerepo.ImageRepository, baseURL string, imageTTL time.Duration) (*Handlers, error) {
+func NewHandlers(text TextModel, image ImageModel, imageRepository imagerepo.ImageRepository, sessionRepository sessionrepo.SessionRepository, opts Options) (*Handlers, error) {
 	if text == nil {
 		return nil, errors.New("text model should not be nil")
 	}
@@ -47,13 +193,89 @@ func NewHandlers(text TextModel, image ImageModel, imageRepository imagerepo.Ima
 	if imageRepository == nil {
 		return nil, errors.New("image repository should not be nil")
 	}
-	return &Handlers{
-		text:            text,
-		image:           image,
-		imageRepository: imageRepository,
-		baseURL:         strings.TrimRight(baseURL, "/"),
-		imageTTL:        imageTTL,
-	}, nil
+	if sessionRepository == nil {
+		return nil, errors.New("session repository should not be nil")
+	}
+	if _, err := opts.ReadPolicy.resolve(""); err != nil {
+		return nil, fmt.Errorf("invalid default read policy %q", opts.ReadPolicy.Default)
+	}
+	if opts.URLSigning.enabled() && opts.URLSigning.TTL <= 0 {
+		return nil, errors.New("signed image links need a positive TTL")
+	}
+	if opts.JobRepository != nil && (opts.Jobs.Workers <= 0 || opts.Jobs.QueueSize <= 0) {
+		return nil, errors.New("job workers and queue size should be positive")
+	}
+	if opts.BatchModel != nil && opts.BatchRepository != nil && (opts.Batches.MaxPrompts <= 0 || opts.Batches.PollInterval <= 0) {
+		return nil, errors.New("batch prompt limit and poll interval should be positive")
+	}
+	h := &Handlers{
+		text:              text,
+		image:             image,
+		imageRepository:   imageRepository,
+		sessionRepository: sessionRepository,
+		jobRepository:     opts.JobRepository,
+		jobTTL:            opts.Jobs.TTL,
+		batchModel:        opts.BatchModel,
+		batchRepository:   opts.BatchRepository,
+		batches:           opts.Batches,
+		imageProcessor:    opts.ImageProcessor,
+		imagePresigner:    opts.ImagePresigner,
+		providers:         opts.Providers,
+		webhooks:          opts.Webhooks,
+		reg:               opts.Metrics,
+		baseURL:           strings.TrimRight(opts.BaseURL, "/"),
+		imageDelivery:     resolveImageDelivery(opts.ImageDelivery, opts.BaseURL, opts.ImagePresigner != nil),
+		imageTTL:          opts.ImageTTL,
+		readPolicy:        opts.ReadPolicy,
+		urlSigning:        opts.URLSigning,
+		upload:            opts.Upload,
+		sessionTTL:        opts.SessionTTL,
+		modelTimeout:      opts.ModelTimeout,
+		maxOutputAttempts: opts.MaxOutputAttempts,
+		generation:        opts.Generation,
+	}
+	if h.jobRepository != nil {
+		h.jobQueue = make(chan imageJob, opts.Jobs.QueueSize)
+		h.jobWorkers = opts.Jobs.Workers
+	}
+	return h, nil
+}
+
+// Start runs the background work of the handlers, the job worker pool,
+// until ctx is done or Close is called. Jobs are queued but not run before
+// Start; batch pollers started by requests also stop with ctx.
+func (h *Handlers) Start(ctx context.Context) {
+	h.background, h.stop = context.WithCancel(ctx)
+	if h.jobQueue != nil {
+		h.startJobWorkers(h.jobWorkers)
+	}
+}
+
+// Close stops the background work and waits for it. Running jobs are
+// cancelled, queued ones fail with 503 shutting_down, and batches are no
+// longer polled.
+func (h *Handlers) Close() {
+	if h.stop != nil {
+		h.stop()
+	}
+	h.wg.Wait()
+	if h.jobQueue != nil {
+		h.failQueuedJobs()
+	}
+}
+
+// detach returns a context with the values of the request context ctx that
+// outlives the request and is cancelled by Close instead.
+func (h *Handlers) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if h.background == nil {
+		return ctx, cancel
+	}
+	stop := context.AfterFunc(h.background, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
 }
 
 // RespondText handles POST /api/v1/chat/text
@@ -62,10 +284,46 @@ func (h *Handlers) RespondText(ctx context.Context, request apigen.RespondTextRe
 		return apigen.RespondText400JSONResponse{Error: "bad_request"}, nil
 	}
 
-	response, err := h.text.SendMessage(request.Body.Text)
+	chatRequest := models.NewTextRequest(request.Body.Text)
+	options, err := h.generation.resolve(request.Body.Options)
+	var invalidOptions *GenerationError
+	if errors.As(err, &invalidOptions) {
+		return apigen.RespondText400JSONResponse(invalidOptions.response()), nil
+	}
+	chatRequest.Options = options
+	if request.Body.SessionId != nil {
+		chatRequest.SessionID = request.Body.SessionId.String()
+		history, ok := h.sessionRepository.Get(ctx, chatRequest.SessionID)
+		if !ok {
+			return apigen.RespondText404JSONResponse{Error: "session_not_found"}, nil
+		}
+		chatRequest.Messages = append(history, chatRequest.Messages...)
+	}
+
+	if request.Body.Stream != nil && *request.Body.Stream {
+		return h.respondTextStream(ctx, chatRequest)
+	}
+
+	modelCtx, cancel := h.modelContext(ctx)
+	defer cancel()
+
+	response, err := h.sendValidated(modelCtx, flowText, chatRequest, h.text.SendMessage)
+	var invalid *OutputInvalidError
+	if errors.As(err, &invalid) {
+		return apigen.RespondText502JSONResponse(invalid.response()), nil
+	}
 	if err != nil {
+		switch h.classifyModelError(modelCtx, flowText, err) {
+		case errorModelNotSupported:
+			return apigen.RespondText400JSONResponse{Error: errorModelNotSupported}, nil
+		case errorClientClosed:
+			return apigen.RespondText499JSONResponse{Error: errorClientClosed}, nil
+		case errorModelTimeout:
+			return apigen.RespondText504JSONResponse{Error: errorModelTimeout}, nil
+		}
 		return nil, err
 	}
+	h.rememberTurn(ctx, chatRequest, firstContent(response))
 
 	// Map assistant messages to the public response shape.
 	var items []apigen.ResponseItem
@@ -73,41 +331,152 @@ func (h *Handlers) RespondText(ctx context.Context, request apigen.RespondTextRe
 		if response.Choices[i].Message.Content != "" {
 			items = append(items, apigen.ResponseItem{
 				Description: response.Choices[i].Message.Content,
+				Analysis:    analyze(response.Choices[i].Message.Content),
 			})
 		}
 	}
+	h.publishAnalysis(ctx, flowText, chatRequest.SessionID, response.Model, nil, items)
 
 	return apigen.RespondText200JSONResponse{Items: items}, nil
 }
 
+// imageChat is a validated POST /api/v1/chat/image request.
+type imageChat struct {
+	form     imageForm
+	options  models.GenerationOptions
+	maxReads int
+}
+
 // ChatImage handles POST /api/v1/chat/image (multipart/form-data)
 func (h *Handlers) ChatImage(ctx context.Context, request apigen.ChatImageRequestObject) (apigen.ChatImageResponseObject, error) {
 	if request.Body == nil {
 		return apigen.ChatImage400JSONResponse{Error: "bad_request"}, nil
 	}
+	chat, rejected := h.readImageChat(request.Body)
+	if rejected != nil {
+		return rejected, nil
+	}
+	return h.runImageChat(ctx, chat)
+}
 
-	imageBytes, ctype, prompt, err := readSingleImagePart(request.Body)
+// readImageChat reads and validates the upload; a rejected request gets a
+// 400 or 413 response instead.
+func (h *Handlers) readImageChat(body *multipart.Reader) (imageChat, apigen.ChatImageResponseObject) {
+	form, err := readImageForm(body, h.upload)
+	var rejected *UploadError
+	if errors.As(err, &rejected) {
+		if rejected.Status == http.StatusRequestEntityTooLarge {
+			return imageChat{}, apigen.ChatImage413JSONResponse(rejected.response())
+		}
+		return imageChat{}, apigen.ChatImage400JSONResponse(rejected.response())
+	}
 	if err != nil {
-		return apigen.ChatImage400JSONResponse{Error: err.Error()}, nil
+		return imageChat{}, apigen.ChatImage400JSONResponse{Error: err.Error()}
 	}
-	if !isSupportedImage(ctype) {
-		return apigen.ChatImage400JSONResponse{Error: "unsupported_media_type"}, nil
+	var chatOptions models.GenerationOptions
+	options, err := parseGenerationOptions(form.Options)
+	if err == nil {
+		chatOptions, err = h.generation.resolve(options)
+	}
+	var invalidOptions *GenerationError
+	if errors.As(err, &invalidOptions) {
+		return imageChat{}, apigen.ChatImage400JSONResponse(invalidOptions.response())
 	}
+	maxReads, err := h.readPolicy.resolve(form.ReadPolicy)
+	if errors.As(err, &rejected) {
+		return imageChat{}, apigen.ChatImage400JSONResponse(rejected.response())
+	}
+	return imageChat{form: form, options: chatOptions, maxReads: maxReads}, nil
+}
 
-	// Save image into temporary repo
-	id, err := h.imageRepository.Save(ctx, imageBytes, h.imageTTL)
+// runImageChat stores the images and asks the vision model about them.
+func (h *Handlers) runImageChat(ctx context.Context, chat imageChat) (apigen.ChatImageResponseObject, error) {
+	form, maxReads := chat.form, chat.maxReads
+	var err error
+	form.Images, err = h.processImages(ctx, form.Images)
+	if errors.Is(err, imaging.ErrInvalidImage) {
+		return apigen.ChatImage400JSONResponse{Error: "invalid_image"}, nil
+	}
 	if err != nil {
 		return apigen.ChatImage500JSONResponse{Error: "internal_error"}, nil
 	}
 
-	imageURL := h.makeImageURL(id)
+	// Save images into temporary repo; the delivery mode only decides
+	// whether the model gets links to them or the bytes themselves.
+	imageIDs, err := h.saveImages(ctx, form.Images, maxReads)
+	if errors.Is(err, imagerepo.ErrStorageFull) {
+		return apigen.ChatImage503JSONResponse{Error: errorStorageFull}, nil
+	}
+	if err != nil {
+		return apigen.ChatImage500JSONResponse{Error: "internal_error"}, nil
+	}
+
+	chatRequest := models.NewTextRequest(form.Prompt)
+	chatRequest.Options = chat.options
+	attempt := 0
+	send := func(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+		attempt++
+		images := make([]models.Image, len(form.Images))
+		for i, image := range form.Images {
+			images[i] = models.Image{ContentType: image.ContentType, Data: image.Data}
+		}
+		if h.imageDelivery == ImageDeliveryURL {
+			ids := imageIDs
+			if attempt > 1 && h.imagePresigner == nil && maxReads != 0 {
+				// Image links run out of reads, so every repair attempt needs fresh copies.
+				fresh, err := h.saveImages(ctx, form.Images, maxReads)
+				if err != nil {
+					return nil, err
+				}
+				ids = fresh
+			}
+			urls, err := h.modelImageURLs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			for i := range images {
+				images[i].URL = urls[i]
+			}
+		}
+		request.Messages = append([]models.ChatMessage(nil), request.Messages...)
+		request.Messages[0].Images = images
+		return h.image.SendImage(ctx, request)
+	}
+
+	modelCtx, cancel := h.modelContext(ctx)
+	defer cancel()
 
 	// Ask the image model to read text from the image and respond
-	response, err := h.image.SendImage(prompt, imageURL)
+	response, err := h.sendValidated(modelCtx, flowImage, chatRequest, send)
+	var invalid *OutputInvalidError
+	if errors.As(err, &invalid) {
+		return apigen.ChatImage502JSONResponse(invalid.response()), nil
+	}
 	if err != nil {
-		return apigen.ChatImage500JSONResponse{Error: "model_error"}, nil
+		if errors.Is(err, imaging.ErrInvalidImage) {
+			return apigen.ChatImage400JSONResponse{Error: "invalid_image"}, nil
+		}
+		if errors.Is(err, imagerepo.ErrStorageFull) {
+			return apigen.ChatImage503JSONResponse{Error: errorStorageFull}, nil
+		}
+		switch code := h.classifyModelError(modelCtx, flowImage, err); code {
+		case errorModelNotSupported, errorImageNotSupported:
+			return apigen.ChatImage400JSONResponse{Error: code}, nil
+		case errorClientClosed:
+			return apigen.ChatImage499JSONResponse{Error: code}, nil
+		case errorModelTimeout:
+			return apigen.ChatImage504JSONResponse{Error: code}, nil
+		default:
+			return apigen.ChatImage500JSONResponse{Error: code}, nil
+		}
 	}
 
+	// The first image is the main one, the rest go to the carousel.
+	imageURLs := make([]string, len(imageIDs))
+	for i, id := range imageIDs {
+		imageURLs[i] = h.makeImageURL(id, "")
+	}
+	mainImageURL, carouselImageURLs := imageURLs[0], imageURLs[1:]
 	var items []apigen.ResponseItem
 	for _, choice := range response.Choices {
 		if choice.Message.Content == "" {
@@ -116,151 +485,342 @@ func (h *Handlers) ChatImage(ctx context.Context, request apigen.ChatImageReques
 		items = append(items, apigen.ResponseItem{
 			Name:              response.Model,
 			Description:       choice.Message.Content,
-			MainImageUrl:      imageURL,
-			CarouselImageUrls: []string{imageURL},
+			Analysis:          analyze(choice.Message.Content),
+			MainImageUrl:      mainImageURL,
+			CarouselImageUrls: carouselImageURLs,
 		})
 	}
+	h.publishAnalysis(ctx, flowImage, "", response.Model, imageIDs, items)
 	if len(items) == 0 {
 		items = []apigen.ResponseItem{{
 			Name:              response.Model,
 			Description:       "(empty response)",
-			MainImageUrl:      imageURL,
-			CarouselImageUrls: []string{imageURL},
+			MainImageUrl:      mainImageURL,
+			CarouselImageUrls: carouselImageURLs,
 		}}
 	}
 
 	return apigen.ChatImage200JSONResponse{Items: items}, nil
 }
 
-// GetStaticImage serves stored image bytes by UUID and deletes them after send.
+// GetStaticImage serves stored image bytes by UUID, counting the download
+// against the image read policy. Conditional requests are answered with
+// 304 and Range requests with 206; neither 304 nor 416 consumes a read,
+// and a 206 consumes one only when it ends with the last byte, so a
+// download in chunks costs a single read.
 func (h *Handlers) GetStaticImage(ctx context.Context, request apigen.GetStaticImageRequestObject) (apigen.GetStaticImageResponseObject, error) {
 	id := request.Id.String()
-	data, ok := h.imageRepository.Get(ctx, id)
-	if !ok || len(data) == 0 {
+	if code := h.verifyImageURL(ctx, id, request.Params.Expires, request.Params.Aud, request.Params.Sig); code != "" {
+		return apigen.GetStaticImage403JSONResponse{Error: code}, nil
+	}
+	if !h.checkCallback(request.Params.Callback) {
+		details := map[string]interface{}{"field": "callback"}
+		return apigen.GetStaticImage400JSONResponse{Error: errorCallbackNotAllowed, Details: &details}, nil
+	}
+	meta, ok := h.imageRepository.Stat(ctx, id)
+	if !ok || meta.Size == 0 {
 		return apigen.GetStaticImage404JSONResponse{Error: "not_found"}, nil
 	}
+	headers := imageHeaders(meta)
+	if notModified(request.Params, meta) {
+		return apigen.GetStaticImage304Response{Headers: apigen.GetStaticImage304ResponseHeaders{
+			ETag:         headers.ETag,
+			Expires:      headers.Expires,
+			LastModified: headers.LastModified,
+		}}, nil
+	}
+	rng, partial, err := requestedRange(request.Params, meta)
+	if errors.Is(err, errRangeNotSatisfiable) {
+		return apigen.GetStaticImage416Response{Headers: apigen.GetStaticImage416ResponseHeaders{
+			ContentRange: fmt.Sprintf("bytes */%d", meta.Size),
+		}}, nil
+	}
 
-	ctype := http.DetectContentType(head(data))
-	// Wrap the bytes with a reader that will delete (and optionally callback) on close.
-	rdr := &deleteOnCloseReader{
-		Reader: bytes.NewReader(data),
+	var rdr io.ReadSeekCloser
+	if !partial || rng.complete() {
+		rdr, meta, ok = h.imageRepository.Read(ctx, id)
+	} else {
+		rdr, meta, ok = h.imageRepository.Open(ctx, id)
+	}
+	if !ok {
+		return apigen.GetStaticImage404JSONResponse{Error: "not_found"}, nil
+	}
+	if meta.MaxReads > 0 && meta.Reads >= meta.MaxReads {
+		h.publish(ctx, webhook.EventImageDeleted, imageEventData(id, meta, reasonReadLimit))
+	}
+	ctype := meta.ContentType
+	if !isSupportedImage(ctype) {
+		// Images saved without a content type.
+		ctype, err = sniffContentType(rdr)
+		if err != nil {
+			rdr.Close()
+			return nil, err
+		}
+	}
+	if !partial {
+		rng = byteRange{length: int64(meta.Size), size: int64(meta.Size)}
+	}
+	if _, err := rdr.Seek(rng.start, io.SeekStart); err != nil {
+		rdr.Close()
+		return nil, err
+	}
+	// Close the stored image with the body and queue the optional callback
+	// once the last byte is delivered.
+	body := &callbackOnCloseReader{
+		Reader: io.LimitReader(rdr, rng.length),
 		onClose: func() {
-			_ = h.imageRepository.Delete(context.Background(), id)
-			// Optional callback
+			rdr.Close()
+			if !rng.complete() {
+				return
+			}
 			if request.Params.Callback != nil && *request.Params.Callback != "" {
-				go postCallback(*request.Params.Callback, id)
+				h.sendImageDelivered(context.WithoutCancel(ctx), *request.Params.Callback, id)
+			}
+			if h.fetchedByModel(request.Params.Aud) {
+				h.publish(ctx, webhook.EventImageFetched, imageEventData(id, meta, ""))
 			}
 		},
 	}
 
+	if partial {
+		partialHeaders := apigen.GetStaticImage206ResponseHeaders{
+			AcceptRanges: headers.AcceptRanges,
+			ContentRange: rng.contentRange(),
+			ETag:         headers.ETag,
+			Expires:      headers.Expires,
+			LastModified: headers.LastModified,
+		}
+		switch ctype {
+		case imaging.TypePNG:
+			return apigen.GetStaticImage206ImagepngResponse{Body: body, Headers: partialHeaders, ContentLength: rng.length}, nil
+		case imaging.TypeGIF:
+			return apigen.GetStaticImage206ImagegifResponse{Body: body, Headers: partialHeaders, ContentLength: rng.length}, nil
+		case imaging.TypeWebP:
+			return apigen.GetStaticImage206ImagewebpResponse{Body: body, Headers: partialHeaders, ContentLength: rng.length}, nil
+		default:
+			return apigen.GetStaticImage206ImagejpegResponse{Body: body, Headers: partialHeaders, ContentLength: rng.length}, nil
+		}
+	}
 	switch ctype {
-	case "image/png":
-		return apigen.GetStaticImage200ImagepngResponse{Body: rdr, ContentLength: int64(len(data))}, nil
-	case "image/jpeg", "image/jpg":
-		return apigen.GetStaticImage200ImagejpegResponse{Body: rdr, ContentLength: int64(len(data))}, nil
+	case imaging.TypePNG:
+		return apigen.GetStaticImage200ImagepngResponse{Body: body, Headers: headers, ContentLength: rng.length}, nil
+	case imaging.TypeGIF:
+		return apigen.GetStaticImage200ImagegifResponse{Body: body, Headers: headers, ContentLength: rng.length}, nil
+	case imaging.TypeWebP:
+		return apigen.GetStaticImage200ImagewebpResponse{Body: body, Headers: headers, ContentLength: rng.length}, nil
 	default:
 		// Default to jpeg content type if undetermined
-		return apigen.GetStaticImage200ImagejpegResponse{Body: rdr, ContentLength: int64(len(data))}, nil
+		return apigen.GetStaticImage200ImagejpegResponse{Body: body, Headers: headers, ContentLength: rng.length}, nil
 	}
 }
 
-// Helpers
+// HeadStaticImage answers HEAD /api/v1/images/{id} from the stored metadata
+// without consuming a read.
+func (h *Handlers) HeadStaticImage(ctx context.Context, request apigen.HeadStaticImageRequestObject) (apigen.HeadStaticImageResponseObject, error) {
+	id := request.Id.String()
+	if code := h.verifyImageURL(ctx, id, request.Params.Expires, request.Params.Aud, request.Params.Sig); code != "" {
+		return apigen.HeadStaticImage403Response{}, nil
+	}
+	meta, ok := h.imageRepository.Stat(ctx, id)
+	if !ok || meta.Size == 0 {
+		return apigen.HeadStaticImage404Response{}, nil
+	}
 
-func (h *Handlers) makeImageURL(id string) string {
-	path := "/api/v1/images/" + id
-	if h.baseURL == "" {
-		return path
+	ctype := meta.ContentType
+	if !isSupportedImage(ctype) {
+		// Images saved without a content type are sniffed like in GET;
+		// Open does not consume a read.
+		rdr, _, ok := h.imageRepository.Open(ctx, id)
+		if !ok {
+			return apigen.HeadStaticImage404Response{}, nil
+		}
+		var err error
+		ctype, err = sniffContentType(rdr)
+		rdr.Close()
+		if err != nil {
+			return nil, err
+		}
 	}
-	return h.baseURL + path
-}
 
-func isSupportedImage(ctype string) bool {
+	headers := apigen.HeadStaticImage200ResponseHeaders(imageHeaders(meta))
+	body, size := bytes.NewReader(nil), int64(meta.Size)
 	switch ctype {
-	case "image/png", "image/jpeg", "image/jpg":
-		return true
+	case imaging.TypePNG:
+		return apigen.HeadStaticImage200ImagepngResponse{Body: body, Headers: headers, ContentLength: size}, nil
+	case imaging.TypeGIF:
+		return apigen.HeadStaticImage200ImagegifResponse{Body: body, Headers: headers, ContentLength: size}, nil
+	case imaging.TypeWebP:
+		return apigen.HeadStaticImage200ImagewebpResponse{Body: body, Headers: headers, ContentLength: size}, nil
+	default:
+		return apigen.HeadStaticImage200ImagejpegResponse{Body: body, Headers: headers, ContentLength: size}, nil
 	}
-	return false
 }
 
-// readSingleImagePart reads a single part named fieldName from multipart.Reader.
-func readSingleImagePart(r *multipart.Reader) ([]byte, string, string, error) {
-	var prompt string
-	var ctype string
-	var buffer []byte
+// Helpers
+
+// verifyImageURL checks the signature of an image link before the image is
+// looked up, so unsigned requests cannot probe ids. It returns the 403
+// error code, or "" when the link is valid or signing is disabled.
+func (h *Handlers) verifyImageURL(ctx context.Context, id string, expires *int64, audience, sig *string) string {
+	if !h.urlSigning.enabled() {
+		return ""
+	}
+	code := h.urlSigning.verify(id, expires, audience, sig, time.Now())
+	if code != "" {
+		log.Ctx(ctx).Warn().Str("image_id", id).Str("reason", code).Msg("image link rejected")
+		if h.reg != nil {
+			h.reg.Inc(ctx, "image_links_rejected_total", map[string]string{"reason": code}, 1)
+		}
+	}
+	return code
+}
+
+// imageHeaders derives caching headers of GET /api/v1/images/{id} from the
+// stored metadata. Images without TTL get an already expired Expires, as
+// they only live until their reads run out.
+func imageHeaders(meta imagerepo.Metadata) apigen.GetStaticImage200ResponseHeaders {
+	headers := apigen.GetStaticImage200ResponseHeaders{
+		AcceptRanges: "bytes",
+		ETag:         quotedETag(meta),
+		Expires:      "0",
+	}
+	if !meta.UploadedAt.IsZero() {
+		headers.LastModified = meta.UploadedAt.UTC().Format(http.TimeFormat)
+	}
+	if !meta.ExpiresAt.IsZero() {
+		headers.Expires = meta.ExpiresAt.UTC().Format(http.TimeFormat)
+	}
+	return headers
+}
 
-	for {
-		part, err := r.NextPart()
+// resolveImageDelivery picks inline delivery in auto mode when there is
+// neither a public BaseURL nor a presigner: a relative link cannot be
+// fetched by the provider.
+func resolveImageDelivery(delivery ImageDelivery, baseURL string, presigned bool) ImageDelivery {
+	switch delivery {
+	case ImageDeliveryURL, ImageDeliveryInline:
+		return delivery
+	}
+	if baseURL == "" && !presigned {
+		return ImageDeliveryInline
+	}
+	return ImageDeliveryURL
+}
 
+// processImages normalises uploads with the configured ImageProcessor.
+func (h *Handlers) processImages(ctx context.Context, images []formImage) ([]formImage, error) {
+	if h.imageProcessor == nil {
+		return images, nil
+	}
+	processed := make([]formImage, 0, len(images))
+	for _, image := range images {
+		out, err := h.imageProcessor.Process(ctx, models.Image{ContentType: image.ContentType, Data: image.Data})
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, "", "", err
+			return nil, err
 		}
-		defer part.Close()
+		processed = append(processed, formImage{Data: out.Data, ContentType: out.ContentType, Filename: image.Filename})
+	}
+	return processed, nil
+}
 
-		switch {
-		case part.FormName() == "text":
-			textBuffer, err := io.ReadAll(part)
-			if err != nil {
-				return nil, "", "", err
+// saveImages stores uploaded images with the maxReads read policy and
+// returns their ids in upload order. On failure images saved so far are
+// removed.
+func (h *Handlers) saveImages(ctx context.Context, images []formImage, maxReads int) ([]string, error) {
+	ids := make([]string, 0, len(images))
+	for _, image := range images {
+		meta := imagerepo.Metadata{
+			ContentType: image.ContentType,
+			RequestID:   middleware.RequestID(ctx),
+			Filename:    image.Filename,
+			MaxReads:    maxReads,
+		}
+		id, err := h.imageRepository.Save(ctx, image.Data, meta, h.imageTTL)
+		if err != nil {
+			// The images were never announced with image.saved, so their
+			// removal is not published either.
+			for _, id := range ids {
+				_ = h.imageRepository.Delete(context.WithoutCancel(ctx), id)
 			}
-			prompt = string(textBuffer)
-			continue
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	h.publishImagesSaved(ctx, ids)
+	return ids, nil
+}
 
-		case part.FormName() == "image":
-			buffer, err = io.ReadAll(part)
-			if err != nil {
-				return nil, "", "", err
-			}
-			ctype = http.DetectContentType(head(buffer))
+// modelImageURLs returns the links vision models fetch stored images by:
+// presigned storage links when available, API links otherwise.
+func (h *Handlers) modelImageURLs(ctx context.Context, ids []string) ([]string, error) {
+	urls := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if h.imagePresigner == nil {
+			urls = append(urls, h.makeImageURL(id, h.urlSigning.ModelAudience))
 			continue
 		}
+		link, err := h.imagePresigner.PresignGet(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, link)
+	}
+	return urls, nil
+}
 
+// makeImageURL returns the link to an image, signed for audience when
+// URLSigning is enabled. Unsigned links still carry the audience, which
+// tells model fetches apart.
+func (h *Handlers) makeImageURL(id, audience string) string {
+	path := "/api/v1/images/" + id
+	if h.urlSigning.enabled() {
+		path += "?" + h.urlSigning.sign(id, audience, time.Now().Add(h.urlSigning.TTL)).Encode()
+	} else if audience != "" {
+		path += "?" + url.Values{"aud": {audience}}.Encode()
+	}
+	if h.baseURL == "" {
+		return path
 	}
+	return h.baseURL + path
+}
+
+// fetchedByModel reports whether an image link was handed to a vision
+// model. Only a signed audience is trusted: without URLSigning anyone can
+// add it to the link.
+func (h *Handlers) fetchedByModel(audience *string) bool {
+	return h.urlSigning.enabled() && audience != nil && h.urlSigning.ModelAudience != "" && *audience == h.urlSigning.ModelAudience
+}
 
-	if len(buffer) != 0 && prompt != "" && ctype != "" {
-		return buffer, ctype, prompt, nil
+// sniffContentType detects the image type from the first bytes of r and
+// rewinds it.
+func sniffContentType(r io.ReadSeeker) (string, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
 	}
-	return nil, "", "", fmt.Errorf("failed to read form")
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return imaging.DetectContentType(head[:n]), nil
 }
 
-func head(b []byte) []byte {
-	if len(b) > 512 {
-		return b[:512]
+func isSupportedImage(ctype string) bool {
+	switch ctype {
+	case imaging.TypePNG, imaging.TypeJPEG, imaging.TypeGIF, imaging.TypeWebP:
+		return true
 	}
-	return b
+	return false
 }
 
-type deleteOnCloseReader struct {
+type callbackOnCloseReader struct {
 	io.Reader
 	onClose func()
 }
 
-func (r *deleteOnCloseReader) Close() error {
+func (r *callbackOnCloseReader) Close() error {
 	if r.onClose != nil {
 		r.onClose()
 	}
 	return nil
 }
-
-func postCallback(url string, id string) {
-	payload := strings.NewReader(fmt.Sprintf(`{"id":"%s","status":"delivered"}`, id))
-	req, err := http.NewRequest(http.MethodPost, url, payload)
-	if err != nil {
-		log.Error().Err(err).Msg("callback build error")
-		return
-	}
-	req.Header.Set("Content-Type", "application/json")
-	// Use default client with a short timeout
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Error().Err(err).Msg("callback send error")
-		return
-	}
-	_ = resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Warn().Str("status", resp.Status).Msg("callback non-2xx status")
-	}
-}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/models"
+	imagerepo "pod_api/pkg/repository/image"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stubImages answers every vision request with five valid items.
+type stubImages struct {
+	requests []models.ChatRequest
+}
+
+func (s *stubImages) SendImage(_ context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	s.requests = append(s.requests, request)
+	return answer("[" + strings.TrimSuffix(strings.Repeat(validItem+",", 5), ",") + "]"), nil
+}
+
+// countingRepository counts saved images.
+type countingRepository struct {
+	imagerepo.ImageRepository
+	saves int
+}
+
+func (r *countingRepository) Save(ctx context.Context, b []byte, meta imagerepo.Metadata, ttl time.Duration) (string, error) {
+	r.saves++
+	return r.ImageRepository.Save(ctx, b, meta, ttl)
+}
+
+func TestResolveImageDelivery(t *testing.T) {
+	tests := []struct {
+		delivery  ImageDelivery
+		baseURL   string
+		presigned bool
+		want      ImageDelivery
+	}{
+		{ImageDeliveryAuto, "", false, ImageDeliveryInline},
+		{ImageDeliveryAuto, "https://pod.example.com", false, ImageDeliveryURL},
+		{ImageDeliveryAuto, "", true, ImageDeliveryURL},
+		{"", "", false, ImageDeliveryInline},
+		{ImageDeliveryURL, "", false, ImageDeliveryURL},
+		{ImageDeliveryInline, "https://pod.example.com", true, ImageDeliveryInline},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, resolveImageDelivery(tt.delivery, tt.baseURL, tt.presigned), "%+v", tt)
+	}
+}
+
+func TestChatImageDelivery(t *testing.T) {
+	png := formImage{Data: []byte("\x89PNG\r\n\x1a\n"), ContentType: "image/png"}
+	const link = "https://pod.example.com/api/v1/images/"
+	tests := []struct {
+		delivery ImageDelivery
+		modelURL bool
+	}{
+		{ImageDeliveryURL, true},
+		{ImageDeliveryInline, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.delivery), func(t *testing.T) {
+			model := &stubImages{}
+			repo := &countingRepository{ImageRepository: imagerepo.NewMemoryRepository(nil, imagerepo.MemoryLimits{})}
+			h := &Handlers{
+				image:           model,
+				imageRepository: repo,
+				imageDelivery:   tt.delivery,
+				imageTTL:        time.Minute,
+				baseURL:         "https://pod.example.com",
+			}
+
+			response, err := h.runImageChat(context.Background(), imageChat{form: imageForm{Images: []formImage{png}, Prompt: "образ"}})
+			require.NoError(t, err)
+			items := response.(apigen.ChatImage200JSONResponse).Items
+			require.Len(t, items, 1)
+			require.Equal(t, 1, repo.saves, "images are stored in every delivery mode")
+			require.True(t, strings.HasPrefix(items[0].MainImageUrl, link), items[0].MainImageUrl)
+			require.Empty(t, items[0].CarouselImageUrls)
+
+			require.Len(t, model.requests, 1)
+			image := model.requests[0].Messages[0].Images[0]
+			require.Equal(t, png.Data, image.Data)
+			if !tt.modelURL {
+				require.Empty(t, image.URL)
+				return
+			}
+			require.True(t, strings.HasPrefix(image.URL, link), image.URL)
+		})
+	}
+}
+
+func TestGetStaticImageReadLimit(t *testing.T) {
+	ctx := context.Background()
+	repo := imagerepo.NewMemoryRepository(nil, imagerepo.MemoryLimits{})
+	h := &Handlers{imageRepository: repo}
+	saved, err := repo.Save(ctx, []byte("\x89PNG\r\n\x1a\nimage"), imagerepo.Metadata{ContentType: "image/png", MaxReads: 1}, time.Minute)
+	require.NoError(t, err)
+	meta, _ := repo.Stat(ctx, saved)
+	id := uuid.MustParse(saved)
+	get := func(params apigen.GetStaticImageParams) apigen.GetStaticImageResponseObject {
+		t.Helper()
+		response, err := h.GetStaticImage(ctx, apigen.GetStaticImageRequestObject{Id: id, Params: params})
+		require.NoError(t, err)
+		return response
+	}
+	body := func(response apigen.GetStaticImageResponseObject) string {
+		t.Helper()
+		reader := response.(apigen.GetStaticImage206ImagepngResponse).Body
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.(io.Closer).Close())
+		return string(data)
+	}
+	reads := func() int {
+		t.Helper()
+		meta, ok := repo.Stat(ctx, saved)
+		require.True(t, ok, "the single read is not consumed yet")
+		return meta.Reads
+	}
+
+	etag := `"` + meta.SHA256 + `"`
+	require.IsType(t, apigen.GetStaticImage304Response{}, get(apigen.GetStaticImageParams{IfNoneMatch: &etag}))
+	require.Zero(t, reads())
+
+	past := "bytes=100-"
+	require.IsType(t, apigen.GetStaticImage416Response{}, get(apigen.GetStaticImageParams{Range: &past}))
+	require.Zero(t, reads())
+
+	head := "bytes=0-7"
+	require.Equal(t, "\x89PNG\r\n\x1a\n", body(get(apigen.GetStaticImageParams{Range: &head})))
+	require.Zero(t, reads(), "a range short of the end does not consume a read")
+
+	tail := "bytes=8-"
+	require.Equal(t, "image", body(get(apigen.GetStaticImageParams{Range: &tail})))
+	_, ok := repo.Stat(ctx, saved)
+	require.False(t, ok, "the final chunk consumes the single read")
+	require.IsType(t, apigen.GetStaticImage404JSONResponse{}, get(apigen.GetStaticImageParams{Range: &head}))
+}
+
+func TestHeadStaticImageSniffsContentType(t *testing.T) {
+	ctx := context.Background()
+	repo := imagerepo.NewMemoryRepository(nil, imagerepo.MemoryLimits{})
+	h := &Handlers{imageRepository: repo}
+	// Stored with an unsupported type, HEAD sniffs the bytes like GET.
+	saved, err := repo.Save(ctx, []byte("\x89PNG\r\n\x1a\nimage"), imagerepo.Metadata{ContentType: "application/octet-stream", MaxReads: 1}, time.Minute)
+	require.NoError(t, err)
+
+	response, err := h.HeadStaticImage(ctx, apigen.HeadStaticImageRequestObject{Id: uuid.MustParse(saved)})
+	require.NoError(t, err)
+	require.IsType(t, apigen.HeadStaticImage200ImagepngResponse{}, response)
+	meta, ok := repo.Stat(ctx, saved)
+	require.True(t, ok)
+	require.Zero(t, meta.Reads, "HEAD does not consume a read")
+}
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	apigen "pod_api/pkg/apigen/openapi"
+	jobrepo "pod_api/pkg/repository/job"
+)
+
+// eventJobCompleted is sent to the POST /api/v1/jobs/image callback once
+// the job finishes.
+const eventJobCompleted = "job.completed"
+
+// Rejections of POST /api/v1/jobs/image, answered with 503. Queued jobs
+// dropped on shutdown fail with errorShuttingDown.
+const (
+	errorQueueFull    = "queue_full"
+	errorJobsDisabled = "jobs_disabled"
+	errorShuttingDown = "shutting_down"
+)
+
+// JobOptions configures asynchronous image analysis.
+type JobOptions struct {
+	// Workers run vision calls of queued jobs concurrently.
+	Workers int
+	// QueueSize bounds jobs waiting for a worker; more are rejected.
+	QueueSize int
+	// TTL is how long a job is kept after its last update.
+	TTL time.Duration
+}
+
+// imageJob is a queued POST /api/v1/jobs/image request.
+type imageJob struct {
+	// ctx carries the request logger and id; it is never cancelled.
+	ctx  context.Context
+	job  jobrepo.Job
+	chat imageChat
+}
+
+// startJobWorkers runs the worker pool of image jobs until h.background
+// is done.
+func (h *Handlers) startJobWorkers(workers int) {
+	for range workers {
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			for {
+				select {
+				case <-h.background.Done():
+					return
+				case task := <-h.jobQueue:
+					h.runImageJob(task)
+				}
+			}
+		}()
+	}
+}
+
+// CreateImageJob handles POST /api/v1/jobs/image (multipart/form-data)
+func (h *Handlers) CreateImageJob(ctx context.Context, request apigen.CreateImageJobRequestObject) (apigen.CreateImageJobResponseObject, error) {
+	if request.Body == nil {
+		return apigen.CreateImageJob400JSONResponse{Error: "bad_request"}, nil
+	}
+	if h.jobRepository == nil {
+		return apigen.CreateImageJob503JSONResponse{Error: errorJobsDisabled}, nil
+	}
+	if !h.checkCallback(request.Params.Callback) {
+		details := map[string]interface{}{"field": "callback"}
+		return apigen.CreateImageJob400JSONResponse{Error: errorCallbackNotAllowed, Details: &details}, nil
+	}
+	chat, rejected := h.readImageChat(request.Body)
+	switch rejected := rejected.(type) {
+	case apigen.ChatImage400JSONResponse:
+		return apigen.CreateImageJob400JSONResponse(rejected), nil
+	case apigen.ChatImage413JSONResponse:
+		return apigen.CreateImageJob413JSONResponse(rejected), nil
+	}
+
+	if h.background != nil && h.background.Err() != nil {
+		return apigen.CreateImageJob503JSONResponse{Error: errorShuttingDown}, nil
+	}
+	// Reject before storing anything, so the client is not left without
+	// the id of a job that never runs.
+	if len(h.jobQueue) == cap(h.jobQueue) {
+		log.Ctx(ctx).Warn().Msg("job queue is full")
+		h.countJob(ctx, "rejected")
+		return apigen.CreateImageJob503JSONResponse{Error: errorQueueFull}, nil
+	}
+
+	job := jobrepo.Job{Status: jobrepo.StatusQueued}
+	if request.Params.Callback != nil {
+		job.Callback = *request.Params.Callback
+	}
+	job, err := h.jobRepository.Create(ctx, job, h.jobTTL)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case h.jobQueue <- imageJob{ctx: context.WithoutCancel(ctx), job: job, chat: chat}:
+	default:
+		// A concurrent request took the last slot in between.
+		log.Ctx(ctx).Warn().Str("job_id", job.ID).Msg("job queue is full")
+		h.countJob(ctx, "rejected")
+		h.failUnstartedJob(ctx, job, errorQueueFull)
+		return apigen.CreateImageJob503JSONResponse{Error: errorQueueFull}, nil
+	}
+
+	return apigen.CreateImageJob202JSONResponse{
+		Body:    toAPIJob(job),
+		Headers: apigen.CreateImageJob202ResponseHeaders{Location: h.baseURL + "/api/v1/jobs/" + job.ID},
+	}, nil
+}
+
+// GetJob handles GET /api/v1/jobs/{id}
+func (h *Handlers) GetJob(ctx context.Context, request apigen.GetJobRequestObject) (apigen.GetJobResponseObject, error) {
+	if h.jobRepository == nil {
+		return apigen.GetJob404JSONResponse{Error: "not_found"}, nil
+	}
+	job, ok := h.jobRepository.Get(ctx, request.Id.String())
+	if !ok {
+		return apigen.GetJob404JSONResponse{Error: "not_found"}, nil
+	}
+	return apigen.GetJob200JSONResponse(toAPIJob(job)), nil
+}
+
+// runImageJob makes the vision call of a job and stores its outcome. The
+// call is cancelled on shutdown.
+func (h *Handlers) runImageJob(task imageJob) {
+	ctx, cancel := h.detach(task.ctx)
+	defer cancel()
+	job := task.job
+	job.Status = jobrepo.StatusRunning
+	job, err := h.jobRepository.Update(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("job_id", task.job.ID).Msg("job expired before it started")
+		return
+	}
+
+	response, err := h.runImageChat(ctx, task.chat)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("job_id", job.ID).Msg("image job failed")
+		response = apigen.ChatImage500JSONResponse{Error: "internal_error"}
+	}
+	if result, ok := response.(apigen.ChatImage200JSONResponse); ok {
+		job.Status = jobrepo.StatusSucceeded
+		job.Result, err = json.Marshal(result)
+	} else {
+		var failure apigen.ErrorResponse
+		job.Status = jobrepo.StatusFailed
+		job.ErrorStatus, failure = chatImageError(response)
+		job.Error, err = json.Marshal(failure)
+	}
+	if err == nil {
+		job, err = h.jobRepository.Update(ctx, job)
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("job_id", job.ID).Msg("job result not stored")
+		return
+	}
+	h.countJob(ctx, string(job.Status))
+	log.Ctx(ctx).Info().Str("job_id", job.ID).Str("status", string(job.Status)).Msg("image job finished")
+
+	if job.Callback != "" {
+		h.sendJobCompleted(ctx, job)
+	}
+}
+
+// failQueuedJobs fails the jobs left in the queue once the workers have
+// stopped, so that clients polling them do not wait for ever.
+func (h *Handlers) failQueuedJobs() {
+	for {
+		select {
+		case task := <-h.jobQueue:
+			log.Ctx(task.ctx).Warn().Str("job_id", task.job.ID).Msg("queued job dropped on shutdown")
+			h.countJob(task.ctx, string(jobrepo.StatusFailed))
+			h.failUnstartedJob(task.ctx, task.job, errorShuttingDown)
+		default:
+			return
+		}
+	}
+}
+
+// failUnstartedJob stores a job that never ran as failed with 503 and code
+// and notifies its callback.
+func (h *Handlers) failUnstartedJob(ctx context.Context, job jobrepo.Job, code string) {
+	job.Status = jobrepo.StatusFailed
+	job.ErrorStatus = http.StatusServiceUnavailable
+	job.Error, _ = json.Marshal(apigen.ErrorResponse{Error: code})
+	stored, err := h.jobRepository.Update(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("job_id", job.ID).Msg("job failure not stored")
+		return
+	}
+	if stored.Callback != "" {
+		h.sendJobCompleted(ctx, stored)
+	}
+}
+
+// sendJobCompleted queues the job completion callback with the job as
+// returned by GET /api/v1/jobs/{id}.
+func (h *Handlers) sendJobCompleted(ctx context.Context, job jobrepo.Job) {
+	body, err := json.Marshal(toAPIJob(job))
+	if err == nil {
+		_, err = h.webhooks.Send(ctx, job.Callback, eventJobCompleted, body)
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("job_id", job.ID).Msg("callback not queued")
+	}
+}
+
+func (h *Handlers) countJob(ctx context.Context, status string) {
+	if h.reg != nil {
+		h.reg.Inc(ctx, "jobs_total", map[string]string{"status": status}, 1)
+	}
+}
+
+// chatImageError returns the status and body of a ChatImage error response.
+func chatImageError(response apigen.ChatImageResponseObject) (int, apigen.ErrorResponse) {
+	switch response := response.(type) {
+	case apigen.ChatImage400JSONResponse:
+		return http.StatusBadRequest, apigen.ErrorResponse(response)
+	case apigen.ChatImage413JSONResponse:
+		return http.StatusRequestEntityTooLarge, apigen.ErrorResponse(response)
+	case apigen.ChatImage502JSONResponse:
+		return http.StatusBadGateway, apigen.ErrorResponse(response)
+	case apigen.ChatImage503JSONResponse:
+		return http.StatusServiceUnavailable, apigen.ErrorResponse(response)
+	case apigen.ChatImage504JSONResponse:
+		return http.StatusGatewayTimeout, apigen.ErrorResponse(response)
+	case apigen.ChatImage500JSONResponse:
+		return http.StatusInternalServerError, apigen.ErrorResponse(response)
+	}
+	return http.StatusInternalServerError, apigen.ErrorResponse{Error: "internal_error"}
+}
+
+func toAPIJob(job jobrepo.Job) apigen.Job {
+	id, _ := uuid.Parse(job.ID)
+	out := apigen.Job{
+		Id:        id,
+		Status:    apigen.JobStatus(job.Status),
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+	if !job.ExpiresAt.IsZero() {
+		expiresAt := job.ExpiresAt
+		out.ExpiresAt = &expiresAt
+	}
+	if len(job.Result) != 0 {
+		var result apigen.CommonResponse
+		if err := json.Unmarshal(job.Result, &result); err == nil {
+			out.Result = &result
+		}
+	}
+	if len(job.Error) != 0 {
+		var failure apigen.ErrorResponse
+		if err := json.Unmarshal(job.Error, &failure); err == nil {
+			out.Error = &failure
+		}
+	}
+	if job.ErrorStatus != 0 {
+		status := job.ErrorStatus
+		out.ErrorStatus = &status
+	}
+	return out
+}
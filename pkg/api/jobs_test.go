@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	apigen "pod_api/pkg/apigen/openapi"
+	imagerepo "pod_api/pkg/repository/image"
+	jobrepo "pod_api/pkg/repository/job"
+	"pod_api/pkg/webhook"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sentWebhook is a webhook queued through stubWebhooks.
+type sentWebhook struct {
+	url   string
+	event string
+	body  []byte
+}
+
+// stubWebhooks allows callbacks to hooks.example.com and records them.
+type stubWebhooks struct {
+	WebhookSender
+
+	mu   sync.Mutex
+	sent []sentWebhook
+}
+
+func (s *stubWebhooks) Check(url string) error {
+	if !strings.HasPrefix(url, "https://hooks.example.com/") {
+		return webhook.ErrDestinationNotAllowed
+	}
+	return nil
+}
+
+func (s *stubWebhooks) Send(_ context.Context, url, event string, body []byte) (webhook.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, sentWebhook{url: url, event: event, body: body})
+	return webhook.Delivery{}, nil
+}
+
+func (s *stubWebhooks) Subscribed(string) bool { return false }
+
+func (s *stubWebhooks) webhooks() []sentWebhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sentWebhook(nil), s.sent...)
+}
+
+func newJobHandlers(queueSize int) (*Handlers, *stubWebhooks) {
+	hooks := &stubWebhooks{}
+	return &Handlers{
+		image:           &stubImages{},
+		imageRepository: imagerepo.NewMemoryRepository(nil, imagerepo.MemoryLimits{}),
+		imageDelivery:   ImageDeliveryInline,
+		jobRepository:   jobrepo.NewMemoryRepository(nil),
+		jobQueue:        make(chan imageJob, queueSize),
+		jobWorkers:      1,
+		jobTTL:          time.Minute,
+		webhooks:        hooks,
+		upload:          UploadLimits{MaxImages: 1, MaxImageSize: 1024, MaxFieldSize: 1024, MaxRequestSize: 4096},
+	}, hooks
+}
+
+func createImageJob(t *testing.T, h *Handlers, callback *string) apigen.CreateImageJobResponseObject {
+	t.Helper()
+	response, err := h.CreateImageJob(context.Background(), apigen.CreateImageJobRequestObject{
+		Params: apigen.CreateImageJobParams{Callback: callback},
+		Body: multipartBody(t,
+			formPart{name: "text", data: []byte("что это?")},
+			formPart{name: "image", data: fakePNG("coat"), file: true},
+		),
+	})
+	require.NoError(t, err)
+	return response
+}
+
+func TestImageJobs(t *testing.T) {
+	ctx := context.Background()
+	h, hooks := newJobHandlers(4)
+	h.Start(ctx)
+	defer h.Close()
+
+	denied := "https://evil.example.com/"
+	require.IsType(t, apigen.CreateImageJob400JSONResponse{}, createImageJob(t, h, &denied))
+
+	callback := "https://hooks.example.com/jobs"
+	created := createImageJob(t, h, &callback).(apigen.CreateImageJob202JSONResponse)
+	require.Equal(t, apigen.JobStatusQueued, created.Body.Status)
+	require.Equal(t, "/api/v1/jobs/"+created.Body.Id.String(), created.Headers.Location)
+
+	var job apigen.Job
+	require.Eventually(t, func() bool {
+		got, err := h.GetJob(ctx, apigen.GetJobRequestObject{Id: created.Body.Id})
+		require.NoError(t, err)
+		job = apigen.Job(got.(apigen.GetJob200JSONResponse))
+		return job.Status == apigen.JobStatusSucceeded
+	}, time.Second, time.Millisecond)
+	require.NotNil(t, job.Result)
+	require.Len(t, job.Result.Items, 1)
+	require.Nil(t, job.Error)
+
+	var sent []sentWebhook
+	require.Eventually(t, func() bool {
+		sent = hooks.webhooks()
+		return len(sent) == 1
+	}, time.Second, time.Millisecond)
+	require.Equal(t, callback, sent[0].url)
+	require.Equal(t, eventJobCompleted, sent[0].event)
+	var delivered apigen.Job
+	require.NoError(t, json.Unmarshal(sent[0].body, &delivered))
+	require.Equal(t, created.Body.Id, delivered.Id)
+	require.Equal(t, apigen.JobStatusSucceeded, delivered.Status)
+
+	missing, err := h.GetJob(ctx, apigen.GetJobRequestObject{Id: [16]byte{1}})
+	require.NoError(t, err)
+	require.IsType(t, apigen.GetJob404JSONResponse{}, missing)
+}
+
+func TestCreateImageJobQueueFull(t *testing.T) {
+	// Without Start nothing drains the queue.
+	h, _ := newJobHandlers(1)
+
+	queued := createImageJob(t, h, nil).(apigen.CreateImageJob202JSONResponse)
+	require.Equal(t, apigen.JobStatusQueued, queued.Body.Status)
+	require.Equal(t, apigen.CreateImageJob503JSONResponse{Error: errorQueueFull}, createImageJob(t, h, nil))
+}
+
+func TestCreateImageJobDisabled(t *testing.T) {
+	h := &Handlers{}
+	require.Equal(t, apigen.CreateImageJob503JSONResponse{Error: errorJobsDisabled}, createImageJob(t, h, nil))
+}
+
+func TestCloseStopsJobWorkers(t *testing.T) {
+	h, _ := newJobHandlers(1)
+	h.Start(context.Background())
+	h.Close()
+
+	require.Equal(t, apigen.CreateImageJob503JSONResponse{Error: errorShuttingDown}, createImageJob(t, h, nil))
+}
+
+func TestCloseFailsQueuedJobs(t *testing.T) {
+	// Without Start the job stays in the queue until Close.
+	h, hooks := newJobHandlers(1)
+	callback := "https://hooks.example.com/jobs"
+	created := createImageJob(t, h, &callback).(apigen.CreateImageJob202JSONResponse)
+	h.Close()
+
+	got, err := h.GetJob(context.Background(), apigen.GetJobRequestObject{Id: created.Body.Id})
+	require.NoError(t, err)
+	job := apigen.Job(got.(apigen.GetJob200JSONResponse))
+	require.Equal(t, apigen.JobStatusFailed, job.Status)
+	require.Equal(t, http.StatusServiceUnavailable, *job.ErrorStatus)
+	require.Equal(t, errorShuttingDown, job.Error.Error)
+	require.Len(t, hooks.webhooks(), 1, "the callback learns about the failure")
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/providers"
+)
+
+// ProviderStatusSource reports provider health (implemented by providers.Registry).
+type ProviderStatusSource interface {
+	Status() []providers.ProviderStatus
+}
+
+// route is implemented by models that fan out to several providers (providers.Chain).
+type route interface {
+	Providers() []string
+}
+
+// ListProviders handles GET /api/v1/providers
+func (h *Handlers) ListProviders(_ context.Context, _ apigen.ListProvidersRequestObject) (apigen.ListProvidersResponseObject, error) {
+	response := apigen.ListProviders200JSONResponse{
+		Providers: []apigen.ProviderStatus{},
+		Routes: apigen.ProviderRoutes{
+			Text:   routeNames(h.text),
+			Vision: routeNames(h.image),
+		},
+	}
+	if h.providers == nil {
+		return response, nil
+	}
+
+	for _, status := range h.providers.Status() {
+		capabilities := make([]string, 0, len(status.Capabilities))
+		for _, capability := range status.Capabilities {
+			capabilities = append(capabilities, string(capability))
+		}
+		breaker := apigen.BreakerStatus{
+			State:               apigen.BreakerStatusState(status.Breaker.State),
+			ConsecutiveFailures: status.Breaker.ConsecutiveFailures,
+			ErrorRate:           status.Breaker.ErrorRate,
+			Calls:               status.Breaker.Calls,
+		}
+		if !status.Breaker.OpenedAt.IsZero() {
+			openedAt := status.Breaker.OpenedAt
+			breaker.OpenedAt = &openedAt
+		}
+		response.Providers = append(response.Providers, apigen.ProviderStatus{
+			Name:         status.Name,
+			Capabilities: capabilities,
+			Breaker:      breaker,
+		})
+	}
+	return response, nil
+}
+
+func routeNames(model any) []string {
+	if r, ok := model.(route); ok {
+		return r.Providers()
+	}
+	return []string{}
+}
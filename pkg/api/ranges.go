@@ -0,0 +1,122 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apigen "pod_api/pkg/apigen/openapi"
+	imagerepo "pod_api/pkg/repository/image"
+)
+
+// errRangeNotSatisfiable rejects a Range that starts past the image end.
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// byteRange is a satisfiable range of an image of size bytes.
+type byteRange struct {
+	start, length, size int64
+}
+
+// contentRange formats the Content-Range header of a 206 response.
+func (r byteRange) contentRange() string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, r.size)
+}
+
+// complete reports whether the range ends with the last byte of the image.
+func (r byteRange) complete() bool {
+	return r.start+r.length == r.size
+}
+
+// notModified evaluates If-None-Match and, without it, If-Modified-Since
+// against the stored image (RFC 9110, section 13.2.2).
+func notModified(params apigen.GetStaticImageParams, meta imagerepo.Metadata) bool {
+	if params.IfNoneMatch != nil {
+		etag := quotedETag(meta)
+		for _, candidate := range strings.Split(*params.IfNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || (etag != "" && strings.TrimPrefix(candidate, "W/") == etag) {
+				return true
+			}
+		}
+		return false
+	}
+	if params.IfModifiedSince != nil && !meta.UploadedAt.IsZero() {
+		since, err := http.ParseTime(*params.IfModifiedSince)
+		return err == nil && !meta.UploadedAt.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// requestedRange returns the range to serve, or false for the whole image.
+// Only single ranges are served; malformed and multi-range headers are
+// ignored, as RFC 9110 allows. If-Range that does not match the stored
+// image also yields the whole image.
+func requestedRange(params apigen.GetStaticImageParams, meta imagerepo.Metadata) (byteRange, bool, error) {
+	if params.Range == nil || meta.Size == 0 {
+		return byteRange{}, false, nil
+	}
+	if params.IfRange != nil && !ifRangeMatches(*params.IfRange, meta) {
+		return byteRange{}, false, nil
+	}
+	spec, ok := strings.CutPrefix(strings.TrimSpace(*params.Range), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return byteRange{}, false, nil
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return byteRange{}, false, nil
+	}
+
+	size := int64(meta.Size)
+	if first == "" {
+		// Suffix range: the last N bytes.
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return byteRange{}, false, nil
+		}
+		if n == 0 {
+			return byteRange{}, false, errRangeNotSatisfiable
+		}
+		n = min(n, size)
+		return byteRange{start: size - n, length: n, size: size}, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, false, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return byteRange{}, false, nil
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return byteRange{}, false, errRangeNotSatisfiable
+	}
+	return byteRange{start: start, length: end - start + 1, size: size}, true, nil
+}
+
+// ifRangeMatches compares If-Range with the strong ETag or the exact
+// Last-Modified date of the image.
+func ifRangeMatches(value string, meta imagerepo.Metadata) bool {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, `"`) {
+		etag := quotedETag(meta)
+		return etag != "" && value == etag
+	}
+	date, err := http.ParseTime(value)
+	return err == nil && !meta.UploadedAt.IsZero() && meta.UploadedAt.Truncate(time.Second).Equal(date)
+}
+
+func quotedETag(meta imagerepo.Metadata) string {
+	if meta.SHA256 == "" {
+		return ""
+	}
+	return `"` + meta.SHA256 + `"`
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	apigen "pod_api/pkg/apigen/openapi"
+	imagerepo "pod_api/pkg/repository/image"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestedRange(t *testing.T) {
+	uploaded := time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC)
+	meta := imagerepo.Metadata{Size: 100, SHA256: "abc", UploadedAt: uploaded}
+
+	tests := map[string]struct {
+		rangeHeader string
+		ifRange     string
+		want        byteRange
+		partial     bool
+		err         error
+	}{
+		"first bytes":            {rangeHeader: "bytes=0-9", want: byteRange{0, 10, 100}, partial: true},
+		"open end":               {rangeHeader: "bytes=90-", want: byteRange{90, 10, 100}, partial: true},
+		"suffix":                 {rangeHeader: "bytes=-20", want: byteRange{80, 20, 100}, partial: true},
+		"end past size":          {rangeHeader: "bytes=50-500", want: byteRange{50, 50, 100}, partial: true},
+		"start past size":        {rangeHeader: "bytes=100-", err: errRangeNotSatisfiable},
+		"malformed":              {rangeHeader: "bytes=x-1"},
+		"other unit":             {rangeHeader: "items=0-1"},
+		"multiple ranges":        {rangeHeader: "bytes=0-1,5-6"},
+		"matching etag":          {rangeHeader: "bytes=0-0", ifRange: `"abc"`, want: byteRange{0, 1, 100}, partial: true},
+		"stale etag":             {rangeHeader: "bytes=0-0", ifRange: `"old"`},
+		"matching date":          {rangeHeader: "bytes=0-0", ifRange: uploaded.Format(http.TimeFormat), want: byteRange{0, 1, 100}, partial: true},
+		"stale date":             {rangeHeader: "bytes=0-0", ifRange: uploaded.Add(-time.Hour).Format(http.TimeFormat)},
+		"weak etag never ranges": {rangeHeader: "bytes=0-0", ifRange: `W/"abc"`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := apigen.GetStaticImageParams{Range: &tt.rangeHeader}
+			if tt.ifRange != "" {
+				params.IfRange = &tt.ifRange
+			}
+			rng, partial, err := requestedRange(params, meta)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.partial, partial)
+			require.Equal(t, tt.want, rng)
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	uploaded := time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC)
+	meta := imagerepo.Metadata{SHA256: "abc", UploadedAt: uploaded}
+
+	require.True(t, notModified(apigen.GetStaticImageParams{IfNoneMatch: ptr(`"old", W/"abc"`)}, meta))
+	require.True(t, notModified(apigen.GetStaticImageParams{IfNoneMatch: ptr("*")}, meta))
+	require.False(t, notModified(apigen.GetStaticImageParams{IfNoneMatch: ptr(`"old"`)}, meta))
+	require.True(t, notModified(apigen.GetStaticImageParams{IfModifiedSince: ptr(uploaded.Format(http.TimeFormat))}, meta))
+	require.False(t, notModified(apigen.GetStaticImageParams{IfModifiedSince: ptr(uploaded.Add(-time.Second).Format(http.TimeFormat))}, meta))
+	// If-None-Match takes precedence over If-Modified-Since.
+	require.False(t, notModified(apigen.GetStaticImageParams{
+		IfNoneMatch:     ptr(`"old"`),
+		IfModifiedSince: ptr(uploaded.Format(http.TimeFormat)),
+	}, meta))
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Read policies accepted in the read_policy field besides a read count.
+const (
+	readPolicySingle = "single"
+	readPolicyTTL    = "ttl"
+)
+
+// ReadPolicy limits how many times an uploaded image can be downloaded
+// through GET /api/v1/images/{id}.
+type ReadPolicy struct {
+	// Default applies to uploads without read_policy: "single", "ttl" or
+	// a read count.
+	Default string
+	// MaxReads caps the read count clients may ask for (0 — no cap).
+	MaxReads int
+}
+
+// resolve returns imagerepo.Metadata.MaxReads for the read_policy value:
+// 1 for "single", 0 for "ttl" and N for a count.
+func (p ReadPolicy) resolve(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = p.Default
+	}
+	switch value {
+	case "", readPolicySingle:
+		return 1, nil
+	case readPolicyTTL:
+		return 0, nil
+	}
+	reads, err := strconv.Atoi(value)
+	if err != nil || reads < 1 || (p.MaxReads > 0 && reads > p.MaxReads) {
+		return 0, &UploadError{Status: http.StatusBadRequest, Code: "invalid_read_policy", Field: "read_policy", Limit: int64(p.MaxReads)}
+	}
+	return reads, nil
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPolicyResolve(t *testing.T) {
+	policy := ReadPolicy{Default: readPolicyTTL, MaxReads: 5}
+
+	cases := map[string]int{"": 0, "single": 1, "ttl": 0, "5": 5, " 2 ": 2}
+	for value, want := range cases {
+		reads, err := policy.resolve(value)
+		require.NoError(t, err, value)
+		require.Equal(t, want, reads, value)
+	}
+
+	for _, value := range []string{"0", "-1", "6", "twice"} {
+		_, err := policy.resolve(value)
+		var rejected *UploadError
+		require.ErrorAs(t, err, &rejected, value)
+		require.Equal(t, "invalid_read_policy", rejected.Code)
+	}
+}
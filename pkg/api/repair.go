@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/fashion"
+	"pod_api/pkg/models"
+	"pod_api/pkg/prompting"
+)
+
+// Flow labels used in metrics.
+const (
+	flowText  = "text"
+	flowImage = "image"
+)
+
+// OutputInvalidError is returned when the model keeps violating the output
+// contract after all repair attempts.
+type OutputInvalidError struct {
+	Attempts int
+	Problems []fashion.Problem
+}
+
+func (e *OutputInvalidError) Error() string {
+	return fmt.Sprintf("model output is invalid after %d attempts: %d problems", e.Attempts, len(e.Problems))
+}
+
+// details renders the error for ErrorResponse.details.
+func (e *OutputInvalidError) details() *map[string]interface{} {
+	return &map[string]interface{}{
+		"attempts": e.Attempts,
+		"problems": toAPIProblems(e.Problems),
+	}
+}
+
+func (e *OutputInvalidError) response() apigen.ErrorResponse {
+	return apigen.ErrorResponse{Error: "model_output_invalid", Details: e.details()}
+}
+
+// sendFunc performs a single model call.
+type sendFunc func(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error)
+
+// sendValidated calls the model and, while the answer violates the output
+// contract, re-asks it with the list of problems. The original request is
+// not modified. Returns *OutputInvalidError if no attempt succeeded.
+func (h *Handlers) sendValidated(ctx context.Context, flow string, request models.ChatRequest, send sendFunc) (*models.ChatResponse, error) {
+	attempts := max(h.maxOutputAttempts, 1)
+	messages := append([]models.ChatMessage(nil), request.Messages...)
+
+	var problems []fashion.Problem
+	for attempt := 1; attempt <= attempts; attempt++ {
+		current := request
+		current.Messages = messages
+
+		response, err := send(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+
+		content := firstContent(response)
+		result := fashion.Parse(content)
+		if result.Valid() {
+			h.countAttempt(ctx, flow, "valid")
+			if attempt > 1 {
+				h.countRepair(ctx, flow, "repaired")
+			}
+			return response, nil
+		}
+		h.countAttempt(ctx, flow, "invalid")
+
+		problems = result.Problems
+		log.Ctx(ctx).Warn().
+			Str("flow", flow).
+			Int("attempt", attempt).
+			Int("problems", len(problems)).
+			Msg("model output violates contract")
+
+		messages = append(messages,
+			models.ChatMessage{Role: models.RoleAssistant, Content: content},
+			models.ChatMessage{Role: models.RoleUser, Content: prompting.RepairPrompt(problemStrings(problems))},
+		)
+	}
+
+	h.countRepair(ctx, flow, "failed")
+	return nil, &OutputInvalidError{Attempts: attempts, Problems: problems}
+}
+
+func (h *Handlers) countAttempt(ctx context.Context, flow, outcome string) {
+	if h.reg != nil {
+		h.reg.Inc(ctx, "model_output_attempts_total", map[string]string{"flow": flow, "outcome": outcome}, 1)
+	}
+}
+
+func (h *Handlers) countRepair(ctx context.Context, flow, result string) {
+	if h.reg != nil {
+		h.reg.Inc(ctx, "model_output_repairs_total", map[string]string{"flow": flow, "result": result}, 1)
+	}
+}
+
+func problemStrings(problems []fashion.Problem) []string {
+	out := make([]string, 0, len(problems))
+	for _, p := range problems {
+		out = append(out, p.String())
+	}
+	return out
+}
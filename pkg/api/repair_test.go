@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pod_api/pkg/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+const validItem = `{"category":"coat","style":"classic","fit":"regular","layer":"outer","formality":"smart","gender":"female","season":"autumn","temperature":"mild","colors":["beige"],"materials":["wool"]}`
+
+func answer(content string) *models.ChatResponse {
+	return &models.ChatResponse{Choices: []models.ChatChoice{{Message: models.ChatMessage{Role: models.RoleAssistant, Content: content}}}}
+}
+
+func TestSendValidatedRepairs(t *testing.T) {
+	h := &Handlers{maxOutputAttempts: 3}
+	valid := "[" + strings.TrimSuffix(strings.Repeat(validItem+",", 5), ",") + "]"
+
+	var requests []models.ChatRequest
+	send := func(_ context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+		requests = append(requests, request)
+		if len(requests) == 1 {
+			return answer("Вот ваш образ: пальто"), nil
+		}
+		return answer(valid), nil
+	}
+
+	response, err := h.sendValidated(context.Background(), flowText, models.NewTextRequest("осень"), send)
+	require.NoError(t, err)
+	require.Equal(t, valid, firstContent(response))
+
+	require.Len(t, requests, 2)
+	require.Len(t, requests[1].Messages, 3)
+	require.Equal(t, models.RoleAssistant, requests[1].Messages[1].Role)
+	require.Contains(t, requests[1].Messages[2].Content, "response is not a JSON array")
+}
+
+func TestSendValidatedGivesUp(t *testing.T) {
+	h := &Handlers{maxOutputAttempts: 2}
+	calls := 0
+	send := func(context.Context, models.ChatRequest) (*models.ChatResponse, error) {
+		calls++
+		return answer("[" + validItem + "]"), nil
+	}
+
+	_, err := h.sendValidated(context.Background(), flowImage, models.NewTextRequest("осень"), send)
+
+	var invalid *OutputInvalidError
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, 2, invalid.Attempts)
+	require.Equal(t, 2, calls)
+	require.Equal(t, "model_output_invalid", invalid.response().Error)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/models"
+	sessionrepo "pod_api/pkg/repository/session"
+)
+
+// CreateSession handles POST /api/v1/sessions
+func (h *Handlers) CreateSession(ctx context.Context, _ apigen.CreateSessionRequestObject) (apigen.CreateSessionResponseObject, error) {
+	id, err := h.sessionRepository.Create(ctx, h.sessionTTL)
+	if err != nil {
+		return apigen.CreateSession500JSONResponse{Error: "internal_error"}, nil
+	}
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return apigen.CreateSession500JSONResponse{Error: "internal_error"}, nil
+	}
+	return apigen.CreateSession201JSONResponse{Id: sessionID}, nil
+}
+
+// DeleteSession handles DELETE /api/v1/sessions/{id}
+func (h *Handlers) DeleteSession(ctx context.Context, request apigen.DeleteSessionRequestObject) (apigen.DeleteSessionResponseObject, error) {
+	err := h.sessionRepository.Delete(ctx, request.Id.String())
+	if errors.Is(err, sessionrepo.ErrNotFound) {
+		return apigen.DeleteSession404JSONResponse{Error: "not_found"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return apigen.DeleteSession204Response{}, nil
+}
+
+// ListSessionMessages handles GET /api/v1/sessions/{id}/messages
+func (h *Handlers) ListSessionMessages(ctx context.Context, request apigen.ListSessionMessagesRequestObject) (apigen.ListSessionMessagesResponseObject, error) {
+	history, ok := h.sessionRepository.Get(ctx, request.Id.String())
+	if !ok {
+		return apigen.ListSessionMessages404JSONResponse{Error: "not_found"}, nil
+	}
+
+	messages := make([]apigen.SessionMessage, 0, len(history))
+	for i := range history {
+		messages = append(messages, apigen.SessionMessage{
+			Role:    apigen.SessionMessageRole(history[i].Role),
+			Content: history[i].Content,
+		})
+	}
+	return apigen.ListSessionMessages200JSONResponse{Id: request.Id, Messages: messages}, nil
+}
+
+// rememberTurn appends the user message and the assistant answer
+// to the session history. Stateless requests are ignored.
+func (h *Handlers) rememberTurn(ctx context.Context, request models.ChatRequest, answer string) {
+	if request.SessionID == "" || len(request.Messages) == 0 || answer == "" {
+		return
+	}
+	userMessage := request.Messages[len(request.Messages)-1]
+	assistantMessage := models.ChatMessage{Role: models.RoleAssistant, Content: answer}
+	if err := h.sessionRepository.Append(ctx, request.SessionID, userMessage, assistantMessage); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("session_id", request.SessionID).Msg("session history not saved")
+	}
+}
+
+// firstContent returns the first non-empty assistant message of a response.
+func firstContent(response *models.ChatResponse) string {
+	for i := range response.Choices {
+		if response.Choices[i].Message.Content != "" {
+			return response.Choices[i].Message.Content
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageURLSigning(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	previous := ImageURLSigning{Keys: [][]byte{[]byte("old-key")}}
+	current := ImageURLSigning{Keys: [][]byte{[]byte("new-key"), []byte("old-key")}}
+	const id = "0b6a3f0e-2f8e-4c52-9b1d-6f1e2c3d4e5f"
+
+	verify := func(s ImageURLSigning, id string, query map[string][]string, at time.Time) string {
+		expires, err := strconv.ParseInt(query["expires"][0], 10, 64)
+		require.NoError(t, err)
+		var aud *string
+		if values := query["aud"]; len(values) != 0 {
+			aud = &values[0]
+		}
+		return s.verify(id, &expires, aud, &query["sig"][0], at)
+	}
+
+	link := current.sign(id, "openai", now.Add(time.Minute))
+	require.Equal(t, "openai", link.Get("aud"))
+	require.Empty(t, verify(current, id, link, now))
+	require.Equal(t, errorSignatureExpired, verify(current, id, link, now.Add(time.Minute)))
+	require.Equal(t, errorSignatureInvalid, verify(current, "1b6a3f0e-2f8e-4c52-9b1d-6f1e2c3d4e5f", link, now))
+	require.Equal(t, errorSignatureInvalid, verify(previous, id, link, now), "signed with the new key")
+
+	// Links signed before the rotation stay valid until they expire.
+	old := previous.sign(id, "", now.Add(time.Minute))
+	require.Empty(t, verify(current, id, old, now))
+
+	tampered := current.sign(id, "", now.Add(time.Minute))
+	tampered.Set("aud", "openai")
+	require.Equal(t, errorSignatureInvalid, verify(current, id, tampered, now))
+	tampered = current.sign(id, "", now.Add(time.Minute))
+	tampered.Set("expires", strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+	require.Equal(t, errorSignatureInvalid, verify(current, id, tampered, now))
+
+	require.Equal(t, errorSignatureRequired, current.verify(id, nil, nil, nil, now))
+}
+
+func TestFetchedByModel(t *testing.T) {
+	model, other := "openai", "browser"
+	signed := &Handlers{urlSigning: ImageURLSigning{Keys: [][]byte{[]byte("key")}, ModelAudience: model}}
+	require.True(t, signed.fetchedByModel(&model))
+	require.False(t, signed.fetchedByModel(&other))
+	require.False(t, signed.fetchedByModel(nil))
+
+	unsigned := &Handlers{urlSigning: ImageURLSigning{ModelAudience: model}}
+	require.False(t, unsigned.fetchedByModel(&model), "an unsigned audience is not trusted")
+}
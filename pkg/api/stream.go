@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/models"
+	"pod_api/pkg/providers"
+)
+
+// Server-sent event names emitted by the streaming text endpoint.
+const (
+	eventDelta = "delta"
+	eventDone  = "done"
+	eventError = "error"
+)
+
+// respondTextStream opens an upstream stream and returns a response object
+// that relays it to the caller as text/event-stream.
+func (h *Handlers) respondTextStream(ctx context.Context, request models.ChatRequest) (apigen.RespondTextResponseObject, error) {
+	streamer, ok := h.text.(StreamingTextModel)
+	if !ok {
+		return apigen.RespondText400JSONResponse{Error: "streaming_not_supported"}, nil
+	}
+
+	// The deadline covers the whole stream, so cancel is handed over to
+	// the response and called once the stream is written.
+	modelCtx, cancel := h.modelContext(ctx)
+	stream, err := streamer.StreamMessage(modelCtx, request)
+	if err != nil {
+		defer cancel()
+		if errors.Is(err, providers.ErrNotSupported) {
+			return apigen.RespondText400JSONResponse{Error: "streaming_not_supported"}, nil
+		}
+		switch h.classifyModelError(modelCtx, flowText, err) {
+		case errorModelNotSupported:
+			return apigen.RespondText400JSONResponse{Error: errorModelNotSupported}, nil
+		case errorClientClosed:
+			return apigen.RespondText499JSONResponse{Error: errorClientClosed}, nil
+		case errorModelTimeout:
+			return apigen.RespondText504JSONResponse{Error: errorModelTimeout}, nil
+		}
+		return nil, err
+	}
+
+	return textStreamResponse{
+		ctx:    modelCtx,
+		cancel: cancel,
+		stream: stream,
+		classify: func(err error) string {
+			return h.classifyModelError(modelCtx, flowText, err)
+		},
+		onComplete: func(content string) {
+			h.rememberTurn(ctx, request, content)
+		},
+	}, nil
+}
+
+// textStreamResponse writes model chunks as SSE events, flushing after each one.
+type textStreamResponse struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	stream models.ChatStream
+	// classify maps a stream failure to an error code for the error event.
+	classify func(err error) string
+	// onComplete receives the whole answer once the stream has finished.
+	onComplete func(content string)
+}
+
+func (r textStreamResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	defer r.cancel()
+	defer r.stream.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Disable proxy buffering (nginx) so deltas reach the client immediately
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	send := func(event string, payload any) error {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	done := apigen.StreamDoneEvent{}
+	var content strings.Builder
+	for {
+		chunk, err := r.stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Headers are already sent: report the failure in-band.
+			return send(eventError, apigen.ErrorResponse{Error: r.classify(err)})
+		}
+
+		if chunk.Model != "" {
+			done.Model = &chunk.Model
+		}
+		if chunk.FinishReason != "" {
+			done.FinishReason = chunk.FinishReason
+		}
+		if chunk.Usage != nil {
+			done.Usage = toAPIUsage(chunk.Usage)
+		}
+		if chunk.Content == "" {
+			continue
+		}
+		content.WriteString(chunk.Content)
+		if err := send(eventDelta, apigen.StreamDeltaEvent{Content: chunk.Content}); err != nil {
+			// Client has gone away
+			return err
+		}
+	}
+
+	if r.onComplete != nil {
+		r.onComplete(content.String())
+	}
+	return send(eventDone, done)
+}
+
+func toAPIUsage(u *models.ChatUsage) *apigen.Usage {
+	return &apigen.Usage{
+		PromptTokens:          &u.PromptTokens,
+		CompletionTokens:      &u.CompletionTokens,
+		TotalTokens:           &u.TotalTokens,
+		PrecachedPromptTokens: &u.PrecachedPromptTokens,
+	}
+}
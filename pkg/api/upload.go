@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/imaging"
+)
+
+// sniffLen is how many bytes http.DetectContentType looks at.
+const sniffLen = 512
+
+// UploadLimits bounds multipart ingestion of POST /api/v1/chat/image.
+// Zero values disable the corresponding limit.
+type UploadLimits struct {
+	// MaxImages limits "image" parts per request.
+	MaxImages int
+	// MaxImageSize limits a single image part, in bytes.
+	MaxImageSize int64
+	// MaxFieldSize limits the "text", "options" and "read_policy" parts, in bytes.
+	MaxFieldSize int64
+	// MaxRequestSize limits all parts of a request together, in bytes.
+	MaxRequestSize int64
+}
+
+// UploadError rejects a multipart upload.
+type UploadError struct {
+	// Status is the HTTP status to answer with (400 or 413).
+	Status int
+	Code   string
+	Field  string
+	// Limit is the exceeded limit, if any.
+	Limit int64
+}
+
+func (e *UploadError) Error() string {
+	if e.Field == "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Field)
+}
+
+func (e *UploadError) response() apigen.ErrorResponse {
+	details := map[string]interface{}{}
+	if e.Field != "" {
+		details["field"] = e.Field
+	}
+	if e.Limit > 0 {
+		details["limit"] = e.Limit
+	}
+	response := apigen.ErrorResponse{Error: e.Code}
+	if len(details) != 0 {
+		response.Details = &details
+	}
+	return response
+}
+
+// imageForm is the parsed multipart body of POST /api/v1/chat/image.
+type imageForm struct {
+	// Images are in upload order; the first one is the main image.
+	Images []formImage
+	Prompt string
+	// Options is the raw JSON of the "options" field.
+	Options string
+	// ReadPolicy is the raw "read_policy" field.
+	ReadPolicy string
+}
+
+// formImage is a single uploaded image.
+type formImage struct {
+	Data        []byte
+	ContentType string
+	// Filename is the name the client sent with the part.
+	Filename string
+}
+
+// readImageForm streams the image, text, options and read_policy parts from
+// multipart.Reader. Every part is read through a limit, so an oversized
+// upload is rejected after at most limit+1 bytes; image types are sniffed
+// before the rest of the part is read.
+func readImageForm(r *multipart.Reader, limits UploadLimits) (imageForm, error) {
+	f := &formReader{
+		limits:    limits,
+		remaining: orUnlimited(limits.MaxRequestSize),
+		seen:      map[string]bool{},
+	}
+
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imageForm{}, err
+		}
+
+		err = f.readPart(part)
+		part.Close()
+		if err != nil {
+			return imageForm{}, err
+		}
+	}
+
+	if len(f.form.Images) != 0 && f.form.Prompt != "" {
+		return f.form, nil
+	}
+	return imageForm{}, fmt.Errorf("failed to read form")
+}
+
+// formReader accumulates parts of a single request.
+type formReader struct {
+	limits UploadLimits
+	// remaining is the request budget left for the following parts.
+	remaining int64
+	seen      map[string]bool
+	form      imageForm
+}
+
+func (f *formReader) readPart(part *multipart.Part) error {
+	name := part.FormName()
+	switch name {
+	case "text", "options", "read_policy":
+		if f.seen[name] {
+			return &UploadError{Status: http.StatusBadRequest, Code: "unexpected_field", Field: name}
+		}
+		f.seen[name] = true
+		data, err := f.readLimited(part, nil, f.limits.MaxFieldSize, "field_too_large", name)
+		if err != nil {
+			return err
+		}
+		switch name {
+		case "text":
+			f.form.Prompt = string(data)
+		case "options":
+			f.form.Options = string(data)
+		default:
+			f.form.ReadPolicy = string(data)
+		}
+		return nil
+
+	case "image":
+		if f.limits.MaxImages > 0 && len(f.form.Images) == f.limits.MaxImages {
+			return &UploadError{Status: http.StatusBadRequest, Code: "too_many_images", Limit: int64(f.limits.MaxImages)}
+		}
+		// Sniff the type before reading the rest of the part.
+		head := make([]byte, sniffLen)
+		n, err := io.ReadFull(part, head)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("failed to read form")
+		}
+		head = head[:n]
+		contentType := imaging.DetectContentType(head)
+		if !isSupportedImage(contentType) {
+			return &UploadError{Status: http.StatusBadRequest, Code: "unsupported_media_type", Field: name}
+		}
+		data, err := f.readLimited(part, head, f.limits.MaxImageSize, "image_too_large", name)
+		if err != nil {
+			return err
+		}
+		f.form.Images = append(f.form.Images, formImage{Data: data, ContentType: contentType, Filename: part.FileName()})
+		return nil
+
+	default:
+		return &UploadError{Status: http.StatusBadRequest, Code: "unexpected_field", Field: name}
+	}
+}
+
+// readLimited reads head followed by the rest of r, failing with 413 once
+// either the part limit or the remaining request budget is exceeded.
+func (f *formReader) readLimited(r io.Reader, head []byte, limit int64, code, field string) ([]byte, error) {
+	limit = orUnlimited(limit)
+	reported := limit
+	if f.remaining < limit {
+		limit, code, reported = f.remaining, "request_too_large", f.limits.MaxRequestSize
+	}
+
+	var buf bytes.Buffer
+	buf.Write(head)
+	if left := limit - int64(buf.Len()); left >= 0 {
+		// One byte past the limit tells an exact fit from an oversized part.
+		if left < math.MaxInt64 {
+			left++
+		}
+		if _, err := buf.ReadFrom(io.LimitReader(r, left)); err != nil {
+			return nil, err
+		}
+	}
+	if int64(buf.Len()) > limit {
+		return nil, &UploadError{Status: http.StatusRequestEntityTooLarge, Code: code, Field: field, Limit: reported}
+	}
+	f.remaining -= int64(buf.Len())
+	return buf.Bytes(), nil
+}
+
+func orUnlimited(limit int64) int64 {
+	if limit <= 0 {
+		return math.MaxInt64
+	}
+	return limit
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakePNG returns bytes that http.DetectContentType reports as image/png.
+func fakePNG(payload string) []byte {
+	return append([]byte("\x89PNG\r\n\x1a\n"), payload...)
+}
+
+// formPart is a multipart field; file parts have a filename.
+type formPart struct {
+	name string
+	data []byte
+	file bool
+}
+
+func multipartBody(t *testing.T, parts ...formPart) *multipart.Reader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, p := range parts {
+		var part io.Writer
+		var err error
+		if p.file {
+			part, err = w.CreateFormFile(p.name, "photo.png")
+		} else {
+			part, err = w.CreateFormField(p.name)
+		}
+		require.NoError(t, err)
+		_, err = part.Write(p.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return multipart.NewReader(&body, w.Boundary())
+}
+
+func TestReadImageForm(t *testing.T) {
+	front, back := fakePNG("front"), fakePNG("back")
+	limits := UploadLimits{MaxImages: 2, MaxImageSize: 64, MaxFieldSize: 64, MaxRequestSize: 1024}
+
+	form, err := readImageForm(multipartBody(t,
+		formPart{name: "text", data: []byte("что это?")},
+		formPart{name: "image", data: front, file: true},
+		formPart{name: "image", data: back, file: true},
+		formPart{name: "read_policy", data: []byte("3")},
+	), limits)
+	require.NoError(t, err)
+	require.Equal(t, "что это?", form.Prompt)
+	require.Equal(t, "3", form.ReadPolicy)
+	require.Len(t, form.Images, 2)
+	require.Equal(t, front, form.Images[0].Data)
+	require.Equal(t, back, form.Images[1].Data)
+	require.Equal(t, "image/png", form.Images[1].ContentType)
+}
+
+func TestReadImageFormRejects(t *testing.T) {
+	limits := UploadLimits{MaxImages: 2, MaxImageSize: 64, MaxFieldSize: 16, MaxRequestSize: 100}
+	text := formPart{name: "text", data: []byte("образ")}
+	image := formPart{name: "image", data: fakePNG("front"), file: true}
+
+	tests := map[string]struct {
+		parts  []formPart
+		status int
+		code   string
+	}{
+		"too many images": {
+			parts:  []formPart{text, image, image, image},
+			status: http.StatusBadRequest, code: "too_many_images",
+		},
+		"image over part limit": {
+			parts:  []formPart{text, {name: "image", data: fakePNG(strings.Repeat("x", 64)), file: true}},
+			status: http.StatusRequestEntityTooLarge, code: "image_too_large",
+		},
+		"field over part limit": {
+			parts:  []formPart{{name: "text", data: []byte(strings.Repeat("x", 17))}, image},
+			status: http.StatusRequestEntityTooLarge, code: "field_too_large",
+		},
+		"request over limit": {
+			parts: []formPart{
+				{name: "text", data: []byte(strings.Repeat("x", 16))},
+				{name: "options", data: []byte(strings.Repeat("x", 16))},
+				{name: "image", data: fakePNG(strings.Repeat("x", 50)), file: true},
+				{name: "image", data: fakePNG(strings.Repeat("x", 50)), file: true},
+			},
+			status: http.StatusRequestEntityTooLarge, code: "request_too_large",
+		},
+		"unsupported type": {
+			parts:  []formPart{text, {name: "image", data: []byte("plain text"), file: true}},
+			status: http.StatusBadRequest, code: "unsupported_media_type",
+		},
+		"unexpected field": {
+			parts:  []formPart{text, {name: "comment", data: []byte("?")}, image},
+			status: http.StatusBadRequest, code: "unexpected_field",
+		},
+		"duplicate field": {
+			parts:  []formPart{text, text, image},
+			status: http.StatusBadRequest, code: "unexpected_field",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := readImageForm(multipartBody(t, tt.parts...), limits)
+			var rejected *UploadError
+			require.ErrorAs(t, err, &rejected)
+			require.Equal(t, tt.status, rejected.Status)
+			require.Equal(t, tt.code, rejected.Code)
+		})
+	}
+}
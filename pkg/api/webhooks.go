@@ -0,0 +1,292 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	apigen "pod_api/pkg/apigen/openapi"
+	"pod_api/pkg/middleware"
+	imagerepo "pod_api/pkg/repository/image"
+	"pod_api/pkg/webhook"
+)
+
+// eventImageDelivered is sent to the GET /api/v1/images/{id} callback once
+// the image is downloaded.
+const eventImageDelivered = "image.delivered"
+
+// errorCallbackNotAllowed rejects callback URLs outside the webhook allowlist.
+const errorCallbackNotAllowed = "callback_not_allowed"
+
+// reasonReadLimit marks image.deleted events of images whose reads ran out.
+const reasonReadLimit = "read_limit"
+
+// maxDeliveriesLimit caps the limit of GET /api/v1/admin/webhooks/deliveries.
+const maxDeliveriesLimit = 1000
+
+// WebhookSender delivers webhooks, publishes lifecycle events to
+// subscriptions and keeps the delivery log (implemented by
+// webhook.Dispatcher).
+type WebhookSender interface {
+	Check(url string) error
+	Send(ctx context.Context, url, event string, body []byte) (webhook.Delivery, error)
+	List(status webhook.Status, limit int) []webhook.Delivery
+	Get(id string) (webhook.Delivery, bool)
+
+	Subscribe(url, secret string, events []string) (webhook.Subscription, error)
+	Unsubscribe(id string) bool
+	Subscriptions() []webhook.Subscription
+	Subscribed(event string) bool
+	Publish(ctx context.Context, event string, data any)
+}
+
+// checkCallback validates a callback URL before the request does any work.
+func (h *Handlers) checkCallback(callback *string) bool {
+	if callback == nil || *callback == "" {
+		return true
+	}
+	return h.webhooks != nil && h.webhooks.Check(*callback) == nil
+}
+
+// sendImageDelivered queues the image download callback.
+func (h *Handlers) sendImageDelivered(ctx context.Context, callback, id string) {
+	body, err := json.Marshal(map[string]string{"id": id, "status": "delivered"})
+	if err == nil {
+		_, err = h.webhooks.Send(ctx, callback, eventImageDelivered, body)
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("callback not queued")
+	}
+}
+
+// subscribed reports whether anyone listens to event, so that payloads are
+// only built when needed.
+func (h *Handlers) subscribed(event string) bool {
+	return h.webhooks != nil && h.webhooks.Subscribed(event)
+}
+
+// publish queues a lifecycle event; it outlives the request.
+func (h *Handlers) publish(ctx context.Context, event string, data any) {
+	if h.subscribed(event) {
+		h.webhooks.Publish(context.WithoutCancel(ctx), event, data)
+	}
+}
+
+// publishImagesSaved reports stored uploads.
+func (h *Handlers) publishImagesSaved(ctx context.Context, ids []string) {
+	if !h.subscribed(webhook.EventImageSaved) {
+		return
+	}
+	for _, id := range ids {
+		if meta, ok := h.imageRepository.Stat(ctx, id); ok {
+			h.publish(ctx, webhook.EventImageSaved, imageEventData(id, meta, ""))
+		}
+	}
+}
+
+// publishAnalysis reports a completed model answer with its parsed result.
+func (h *Handlers) publishAnalysis(ctx context.Context, flow string, sessionID, model string, imageIDs []string, items []apigen.ResponseItem) {
+	if !h.subscribed(webhook.EventAnalysisCompleted) {
+		return
+	}
+	data := apigen.WebhookAnalysisData{Flow: flow, Results: []apigen.WebhookAnalysisResult{}}
+	if requestID := middleware.RequestID(ctx); requestID != "" {
+		data.RequestId = &requestID
+	}
+	if id, err := uuid.Parse(sessionID); err == nil {
+		data.SessionId = &id
+	}
+	if model != "" {
+		data.Model = &model
+	}
+	if len(imageIDs) != 0 {
+		ids := make([]uuid.UUID, 0, len(imageIDs))
+		for _, id := range imageIDs {
+			parsed, _ := uuid.Parse(id)
+			ids = append(ids, parsed)
+		}
+		data.ImageIds = &ids
+	}
+	for _, item := range items {
+		if item.Analysis != nil {
+			data.Results = append(data.Results, apigen.WebhookAnalysisResult{Description: item.Description, Analysis: *item.Analysis})
+		}
+	}
+	h.publish(ctx, webhook.EventAnalysisCompleted, data)
+}
+
+// ImageRemoved publishes images the repository removed on its own; it is
+// wired to imagerepo.RemoveNotifier.OnRemove.
+func (h *Handlers) ImageRemoved(ctx context.Context, id string, meta imagerepo.Metadata, reason imagerepo.RemoveReason) {
+	if reason == imagerepo.RemovedExpired {
+		h.publish(ctx, webhook.EventImageExpired, imageEventData(id, meta, ""))
+		return
+	}
+	h.publish(ctx, webhook.EventImageDeleted, imageEventData(id, meta, string(reason)))
+}
+
+func imageEventData(id string, meta imagerepo.Metadata, reason string) apigen.WebhookImageData {
+	imageID, _ := uuid.Parse(id)
+	data := apigen.WebhookImageData{
+		Id:          imageID,
+		ContentType: meta.ContentType,
+		Size:        meta.Size,
+		Sha256:      meta.SHA256,
+		UploadedAt:  meta.UploadedAt,
+		Reads:       meta.Reads,
+	}
+	if !meta.ExpiresAt.IsZero() {
+		expiresAt := meta.ExpiresAt
+		data.ExpiresAt = &expiresAt
+	}
+	if meta.MaxReads != 0 {
+		maxReads := meta.MaxReads
+		data.MaxReads = &maxReads
+	}
+	if meta.RequestID != "" {
+		requestID := meta.RequestID
+		data.RequestId = &requestID
+	}
+	if reason != "" {
+		data.Reason = &reason
+	}
+	return data
+}
+
+// ListWebhookSubscriptions handles GET /api/v1/admin/webhooks/subscriptions
+func (h *Handlers) ListWebhookSubscriptions(_ context.Context, _ apigen.ListWebhookSubscriptionsRequestObject) (apigen.ListWebhookSubscriptionsResponseObject, error) {
+	response := apigen.ListWebhookSubscriptions200JSONResponse{Subscriptions: []apigen.WebhookSubscription{}}
+	if h.webhooks == nil {
+		return response, nil
+	}
+	for _, sub := range h.webhooks.Subscriptions() {
+		response.Subscriptions = append(response.Subscriptions, webhookSubscription(sub, false))
+	}
+	return response, nil
+}
+
+// CreateWebhookSubscription handles POST /api/v1/admin/webhooks/subscriptions
+func (h *Handlers) CreateWebhookSubscription(_ context.Context, request apigen.CreateWebhookSubscriptionRequestObject) (apigen.CreateWebhookSubscriptionResponseObject, error) {
+	if request.Body == nil {
+		return apigen.CreateWebhookSubscription400JSONResponse{Error: "bad_request"}, nil
+	}
+	if h.webhooks == nil {
+		details := map[string]interface{}{"field": "url"}
+		return apigen.CreateWebhookSubscription400JSONResponse{Error: errorCallbackNotAllowed, Details: &details}, nil
+	}
+	var secret string
+	if request.Body.Secret != nil {
+		secret = *request.Body.Secret
+	}
+	events := make([]string, 0, len(request.Body.Events))
+	for _, event := range request.Body.Events {
+		events = append(events, string(event))
+	}
+
+	sub, err := h.webhooks.Subscribe(request.Body.Url, secret, events)
+	switch {
+	case errors.Is(err, webhook.ErrDestinationNotAllowed):
+		details := map[string]interface{}{"field": "url"}
+		return apigen.CreateWebhookSubscription400JSONResponse{Error: errorCallbackNotAllowed, Details: &details}, nil
+	case errors.Is(err, webhook.ErrUnknownEvent):
+		details := map[string]interface{}{"field": "events", "allowed": webhook.EventTypes}
+		return apigen.CreateWebhookSubscription400JSONResponse{Error: "bad_request", Details: &details}, nil
+	case err != nil:
+		return nil, err
+	}
+	return apigen.CreateWebhookSubscription201JSONResponse(webhookSubscription(sub, true)), nil
+}
+
+// DeleteWebhookSubscription handles DELETE /api/v1/admin/webhooks/subscriptions/{id}
+func (h *Handlers) DeleteWebhookSubscription(_ context.Context, request apigen.DeleteWebhookSubscriptionRequestObject) (apigen.DeleteWebhookSubscriptionResponseObject, error) {
+	if h.webhooks == nil || !h.webhooks.Unsubscribe(request.Id.String()) {
+		return apigen.DeleteWebhookSubscription404JSONResponse{Error: "not_found"}, nil
+	}
+	return apigen.DeleteWebhookSubscription204Response{}, nil
+}
+
+func webhookSubscription(sub webhook.Subscription, withSecret bool) apigen.WebhookSubscription {
+	id, _ := uuid.Parse(sub.ID)
+	out := apigen.WebhookSubscription{
+		Id:        id,
+		Url:       sub.URL,
+		CreatedAt: sub.CreatedAt,
+		Events:    make([]apigen.WebhookEventType, 0, len(sub.Events)),
+	}
+	for _, event := range sub.Events {
+		out.Events = append(out.Events, apigen.WebhookEventType(event))
+	}
+	if withSecret {
+		secret := sub.Secret
+		out.Secret = &secret
+	}
+	return out
+}
+
+// ListWebhookDeliveries handles GET /api/v1/admin/webhooks/deliveries
+func (h *Handlers) ListWebhookDeliveries(_ context.Context, request apigen.ListWebhookDeliveriesRequestObject) (apigen.ListWebhookDeliveriesResponseObject, error) {
+	limit := 100
+	if request.Params.Limit != nil {
+		limit = *request.Params.Limit
+	}
+	if limit < 1 || limit > maxDeliveriesLimit {
+		details := map[string]interface{}{"field": "limit", "limit": maxDeliveriesLimit}
+		return apigen.ListWebhookDeliveries400JSONResponse{Error: "bad_request", Details: &details}, nil
+	}
+	var status webhook.Status
+	if request.Params.Status != nil {
+		status = webhook.Status(*request.Params.Status)
+	}
+
+	response := apigen.ListWebhookDeliveries200JSONResponse{Deliveries: []apigen.WebhookDelivery{}}
+	if h.webhooks == nil {
+		return response, nil
+	}
+	for _, delivery := range h.webhooks.List(status, limit) {
+		response.Deliveries = append(response.Deliveries, webhookDelivery(delivery))
+	}
+	return response, nil
+}
+
+// GetWebhookDelivery handles GET /api/v1/admin/webhooks/deliveries/{id}
+func (h *Handlers) GetWebhookDelivery(_ context.Context, request apigen.GetWebhookDeliveryRequestObject) (apigen.GetWebhookDeliveryResponseObject, error) {
+	if h.webhooks == nil {
+		return apigen.GetWebhookDelivery404JSONResponse{Error: "not_found"}, nil
+	}
+	delivery, ok := h.webhooks.Get(request.Id.String())
+	if !ok {
+		return apigen.GetWebhookDelivery404JSONResponse{Error: "not_found"}, nil
+	}
+	return apigen.GetWebhookDelivery200JSONResponse(webhookDelivery(delivery)), nil
+}
+
+func webhookDelivery(delivery webhook.Delivery) apigen.WebhookDelivery {
+	id, _ := uuid.Parse(delivery.ID)
+	out := apigen.WebhookDelivery{
+		Id:        id,
+		Event:     delivery.Event,
+		Url:       delivery.URL,
+		Status:    apigen.WebhookDeliveryStatus(delivery.Status),
+		Attempts:  delivery.Attempts,
+		CreatedAt: delivery.CreatedAt,
+		UpdatedAt: delivery.UpdatedAt,
+	}
+	if delivery.LastStatusCode != 0 {
+		code := delivery.LastStatusCode
+		out.LastStatusCode = &code
+	}
+	if delivery.LastError != "" {
+		lastError := delivery.LastError
+		out.LastError = &lastError
+	}
+	if subscriptionID, err := uuid.Parse(delivery.SubscriptionID); err == nil {
+		out.SubscriptionId = &subscriptionID
+	}
+	if !delivery.NextAttemptAt.IsZero() {
+		next := delivery.NextAttemptAt
+		out.NextAttemptAt = &next
+	}
+	return out
+}
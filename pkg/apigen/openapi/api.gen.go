@@ -10,6 +10,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/oapi-codegen/runtime"
@@ -17,10 +18,132 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for BatchStatus.
+const (
+	BatchStatusCompleted  BatchStatus = "completed"
+	BatchStatusCreated    BatchStatus = "created"
+	BatchStatusFailed     BatchStatus = "failed"
+	BatchStatusInProgress BatchStatus = "in_progress"
+)
+
+// Defines values for BreakerStatusState.
+const (
+	Closed   BreakerStatusState = "closed"
+	HalfOpen BreakerStatusState = "half_open"
+	Open     BreakerStatusState = "open"
+)
+
+// Defines values for JobStatus.
+const (
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+)
+
+// Defines values for SessionMessageRole.
+const (
+	Assistant SessionMessageRole = "assistant"
+	User      SessionMessageRole = "user"
+)
+
+// Defines values for WebhookDeliveryStatus.
+const (
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+)
+
+// Defines values for WebhookEventType.
+const (
+	AnalysisCompleted WebhookEventType = "analysis.completed"
+	ImageDeleted      WebhookEventType = "image.deleted"
+	ImageExpired      WebhookEventType = "image.expired"
+	ImageFetched      WebhookEventType = "image.fetched"
+	ImageSaved        WebhookEventType = "image.saved"
+)
+
+// Batch Batch of text prompts; items carry answers once completed
+type Batch struct {
+	// Completed Prompts answered so far
+	Completed int       `json:"completed"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Error Why a failed batch has no results
+	Error *string `json:"error,omitempty"`
+
+	// ExpiresAt When the batch is removed (BATCH_TTL after the last update)
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// Failed Prompts the provider failed to answer
+	Failed    int                `json:"failed"`
+	Id        openapi_types.UUID `json:"id"`
+	Items     []BatchItem        `json:"items"`
+	Status    BatchStatus        `json:"status"`
+	Total     int                `json:"total"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+}
+
+// BatchItem defines model for BatchItem.
+type BatchItem struct {
+	// Analysis Model answer parsed and validated against the fashion item contract
+	Analysis *FashionAnalysis `json:"analysis,omitempty"`
+
+	// Description Model answer
+	Description *string `json:"description,omitempty"`
+
+	// Error Why the prompt was not answered
+	Error *string `json:"error,omitempty"`
+
+	// Index Position of the prompt in the request
+	Index  int    `json:"index"`
+	Prompt string `json:"prompt"`
+}
+
+// BatchRequest defines model for BatchRequest.
+type BatchRequest struct {
+	// Prompts Text prompts, each answered separately (up to BATCH_MAX_PROMPTS)
+	Prompts []string `json:"prompts"`
+}
+
+// BatchStatus defines model for BatchStatus.
+type BatchStatus string
+
+// BreakerStatus defines model for BreakerStatus.
+type BreakerStatus struct {
+	// Calls Number of calls in the recent window
+	Calls               int `json:"calls"`
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+
+	// ErrorRate Share of failed calls in the recent window
+	ErrorRate float64 `json:"errorRate"`
+
+	// OpenedAt When the breaker was opened; absent while closed
+	OpenedAt *time.Time         `json:"openedAt,omitempty"`
+	State    BreakerStatusState `json:"state"`
+}
+
+// BreakerStatusState defines model for BreakerStatus.State.
+type BreakerStatusState string
+
 // ChatImageRequest defines model for ChatImageRequest.
 type ChatImageRequest struct {
-	// Image Загруженное изображение (PNG/JPEG), содержащее текст
-	Image openapi_types.File `json:"image"`
+	// Image Загруженные изображения (PNG/JPEG/WebP/GIF) — часть "image" можно повторить,
+	// например фото спереди, сзади и бирки. Количество и общий размер
+	// ограничены IMAGE_MAX_COUNT и UPLOAD_MAX_*_SIZE.
+	Image []openapi_types.File `json:"image"`
+
+	// Options GenerationOptions в виде JSON
+	Options *string `json:"options,omitempty"`
+
+	// ReadPolicy Сколько раз можно скачать каждое изображение по ссылке:
+	// "single", число N или "ttl" (без ограничения до истечения TTL).
+	// По умолчанию IMAGE_READ_POLICY.
+	ReadPolicy *string `json:"read_policy,omitempty"`
 
 	// Text Промт пользователя
 	Text *string `json:"text,omitempty"`
@@ -40,34 +163,379 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// FashionAnalysis Model answer parsed and validated against the fashion item contract
+type FashionAnalysis struct {
+	// Items Items that passed validation
+	Items []FashionItem `json:"items"`
+
+	// Problems Contract violations found in the answer
+	Problems []ValidationProblem `json:"problems"`
+
+	// Refusal Set when the model declined the request, e.g. not_fashion_related
+	Refusal *string `json:"refusal,omitempty"`
+}
+
+// FashionItem Clothing item; values follow the closed vocabularies of pkg/fashion
+type FashionItem struct {
+	Category    string   `json:"category"`
+	Colors      []string `json:"colors"`
+	Fit         string   `json:"fit"`
+	Formality   string   `json:"formality"`
+	Gender      string   `json:"gender"`
+	Layer       string   `json:"layer"`
+	Materials   []string `json:"materials"`
+	Season      string   `json:"season"`
+	Style       string   `json:"style"`
+	Temperature string   `json:"temperature"`
+}
+
+// GenerationOptions Optional generation parameters; omitted fields keep provider defaults.
+// Only options listed in GENERATION_ALLOWED_PARAMS are accepted.
+type GenerationOptions struct {
+	// MaxTokens Capped by the model limit from GENERATION_MODELS or GENERATION_MAX_TOKENS
+	MaxTokens *int `json:"max_tokens,omitempty"`
+
+	// Model Model name, must be listed in GENERATION_MODELS
+	Model             *string  `json:"model,omitempty"`
+	RepetitionPenalty *float64 `json:"repetition_penalty,omitempty"`
+
+	// Temperature Must be greater than 0 when GigaChat is among the providers
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+// Job Asynchronous request; result is set once succeeded, error once failed
+type Job struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Error Error wrapper
+	Error *ErrorResponse `json:"error,omitempty"`
+
+	// ErrorStatus HTTP status /api/v1/chat/image would have answered with
+	ErrorStatus *int `json:"errorStatus,omitempty"`
+
+	// ExpiresAt When the job is removed (JOB_TTL after the last update)
+	ExpiresAt *time.Time         `json:"expiresAt,omitempty"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// Result Common response wrapper
+	Result    *CommonResponse `json:"result,omitempty"`
+	Status    JobStatus       `json:"status"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// JobStatus defines model for JobStatus.
+type JobStatus string
+
+// ProviderRoutes Provider names in fallback order per capability
+type ProviderRoutes struct {
+	Text   []string `json:"text"`
+	Vision []string `json:"vision"`
+}
+
+// ProviderStatus defines model for ProviderStatus.
+type ProviderStatus struct {
+	Breaker      BreakerStatus `json:"breaker"`
+	Capabilities []string      `json:"capabilities"`
+	Name         string        `json:"name"`
+}
+
+// ProvidersStatus defines model for ProvidersStatus.
+type ProvidersStatus struct {
+	Providers []ProviderStatus `json:"providers"`
+
+	// Routes Provider names in fallback order per capability
+	Routes ProviderRoutes `json:"routes"`
+}
+
 // ResponseItem defines model for ResponseItem.
 type ResponseItem struct {
+	// Analysis Model answer parsed and validated against the fashion item contract
+	Analysis *FashionAnalysis `json:"analysis,omitempty"`
+
+	// CarouselImageUrls Links to the other uploaded images
 	CarouselImageUrls []string `json:"carouselImageUrls"`
 	Description       string   `json:"description"`
-	MainImageUrl      string   `json:"mainImageUrl"`
-	Name              string   `json:"name"`
+
+	// MainImageUrl Link to the first uploaded image
+	MainImageUrl string `json:"mainImageUrl"`
+	Name         string `json:"name"`
+}
+
+// SessionHistory defines model for SessionHistory.
+type SessionHistory struct {
+	Id       openapi_types.UUID `json:"id"`
+	Messages []SessionMessage   `json:"messages"`
+}
+
+// SessionMessage defines model for SessionMessage.
+type SessionMessage struct {
+	Content string             `json:"content"`
+	Role    SessionMessageRole `json:"role"`
+}
+
+// SessionMessageRole defines model for SessionMessage.Role.
+type SessionMessageRole string
+
+// SessionResponse defines model for SessionResponse.
+type SessionResponse struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+// StreamDeltaEvent Payload of the `delta` server-sent event
+type StreamDeltaEvent struct {
+	// Content Next piece of the generated text
+	Content string `json:"content"`
+}
+
+// StreamDoneEvent Payload of the final `done` server-sent event
+type StreamDoneEvent struct {
+	// FinishReason Reason the model stopped generating
+	FinishReason string  `json:"finishReason"`
+	Model        *string `json:"model,omitempty"`
+
+	// Usage Token accounting
+	Usage *Usage `json:"usage,omitempty"`
 }
 
 // TextRequest defines model for TextRequest.
 type TextRequest struct {
+	// Options Optional generation parameters; omitted fields keep provider defaults.
+	// Only options listed in GENERATION_ALLOWED_PARAMS are accepted.
+	Options *GenerationOptions `json:"options,omitempty"`
+
+	// SessionId Continue the conversation of this session
+	SessionId *openapi_types.UUID `json:"session_id,omitempty"`
+
+	// Stream Stream the answer as server-sent events
+	Stream *bool `json:"stream,omitempty"`
+
 	// Text Input text
 	Text string `json:"text"`
 }
 
+// Usage Token accounting
+type Usage struct {
+	CompletionTokens      *int32 `json:"completionTokens,omitempty"`
+	PrecachedPromptTokens *int32 `json:"precachedPromptTokens,omitempty"`
+	PromptTokens          *int32 `json:"promptTokens,omitempty"`
+	TotalTokens           *int32 `json:"totalTokens,omitempty"`
+}
+
+// ValidationProblem Single violation of the model output contract
+type ValidationProblem struct {
+	// Field Offending field name
+	Field *string `json:"field,omitempty"`
+
+	// Item Zero-based index of the offending item; absent for response-level problems
+	Item    *int   `json:"item,omitempty"`
+	Message string `json:"message"`
+}
+
+// WebhookAnalysisData defines model for WebhookAnalysisData.
+type WebhookAnalysisData struct {
+	// Flow text or image
+	Flow      string                  `json:"flow"`
+	ImageIds  *[]openapi_types.UUID   `json:"imageIds,omitempty"`
+	Model     *string                 `json:"model,omitempty"`
+	RequestId *string                 `json:"requestId,omitempty"`
+	Results   []WebhookAnalysisResult `json:"results"`
+	SessionId *openapi_types.UUID     `json:"sessionId,omitempty"`
+}
+
+// WebhookAnalysisResult defines model for WebhookAnalysisResult.
+type WebhookAnalysisResult struct {
+	// Analysis Model answer parsed and validated against the fashion item contract
+	Analysis    FashionAnalysis `json:"analysis"`
+	Description string          `json:"description"`
+}
+
+// WebhookDelivery A webhook sent by the service, as kept in the delivery log
+type WebhookDelivery struct {
+	// Attempts Attempts made so far
+	Attempts  int                `json:"attempts"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Event     string             `json:"event"`
+	Id        openapi_types.UUID `json:"id"`
+	LastError *string            `json:"lastError,omitempty"`
+
+	// LastStatusCode HTTP status of the last attempt; absent if it got no answer
+	LastStatusCode *int `json:"lastStatusCode,omitempty"`
+
+	// NextAttemptAt When the next retry is due; absent unless a retry is scheduled
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+
+	// Status Delivery state
+	Status WebhookDeliveryStatus `json:"status"`
+
+	// SubscriptionId Subscription of an event delivery; absent for callbacks
+	SubscriptionId *openapi_types.UUID `json:"subscriptionId,omitempty"`
+	UpdatedAt      time.Time           `json:"updatedAt"`
+	Url            string              `json:"url"`
+}
+
+// WebhookDeliveryList defines model for WebhookDeliveryList.
+type WebhookDeliveryList struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// WebhookDeliveryStatus Delivery state
+type WebhookDeliveryStatus string
+
+// WebhookEvent Envelope of events sent to subscriptions. Fields are only added
+// within a version. Data by type: WebhookImageData for image.*
+// events (with reason for image.deleted), WebhookAnalysisData for
+// analysis.completed.
+type WebhookEvent struct {
+	CreatedAt time.Time              `json:"createdAt"`
+	Data      map[string]interface{} `json:"data"`
+
+	// Id Event id, the same in deliveries to every subscription
+	Id      openapi_types.UUID `json:"id"`
+	Type    WebhookEventType   `json:"type"`
+	Version string             `json:"version"`
+}
+
+// WebhookEventType defines model for WebhookEventType.
+type WebhookEventType string
+
+// WebhookImageData defines model for WebhookImageData.
+type WebhookImageData struct {
+	ContentType string             `json:"contentType"`
+	ExpiresAt   *time.Time         `json:"expiresAt,omitempty"`
+	Id          openapi_types.UUID `json:"id"`
+
+	// MaxReads Read policy; absent for ttl
+	MaxReads *int `json:"maxReads,omitempty"`
+	Reads    int  `json:"reads"`
+
+	// Reason Why the image was deleted, read_limit or evicted (image.deleted only)
+	Reason *string `json:"reason,omitempty"`
+
+	// RequestId X-Request-ID of the upload
+	RequestId  *string   `json:"requestId,omitempty"`
+	Sha256     string    `json:"sha256"`
+	Size       int       `json:"size"`
+	UploadedAt time.Time `json:"uploadedAt"`
+}
+
+// WebhookSubscription defines model for WebhookSubscription.
+type WebhookSubscription struct {
+	CreatedAt time.Time          `json:"createdAt"`
+	Events    []WebhookEventType `json:"events"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// Secret Signing secret; returned only on creation
+	Secret *string `json:"secret,omitempty"`
+	Url    string  `json:"url"`
+}
+
+// WebhookSubscriptionList defines model for WebhookSubscriptionList.
+type WebhookSubscriptionList struct {
+	Subscriptions []WebhookSubscription `json:"subscriptions"`
+}
+
+// WebhookSubscriptionRequest defines model for WebhookSubscriptionRequest.
+type WebhookSubscriptionRequest struct {
+	Events []WebhookEventType `json:"events"`
+
+	// Secret Signing secret; generated when omitted
+	Secret *string `json:"secret,omitempty"`
+	Url    string  `json:"url"`
+}
+
+// ListWebhookDeliveriesParams defines parameters for ListWebhookDeliveries.
+type ListWebhookDeliveriesParams struct {
+	// Status Only deliveries in this state
+	Status *WebhookDeliveryStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Limit Maximum number of deliveries, newest first
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // GetStaticImageParams defines parameters for GetStaticImage.
 type GetStaticImageParams struct {
-	// Callback URL для обратного вызова после скачивания
+	// Callback URL вебхука после скачивания; хост должен быть в WEBHOOK_ALLOWED_HOSTS
+	Callback *string `form:"callback,omitempty" json:"callback,omitempty"`
+
+	// Expires Срок действия подписанной ссылки, Unix‑время
+	Expires *int64 `form:"expires,omitempty" json:"expires,omitempty"`
+
+	// Aud Получатель, для которого выдана ссылка
+	Aud *string `form:"aud,omitempty" json:"aud,omitempty"`
+
+	// Sig HMAC‑подпись ссылки (при IMAGE_URL_SIGNING_KEY)
+	Sig *string `form:"sig,omitempty" json:"sig,omitempty"`
+
+	// Range Один диапазон байт, например bytes=0-1023
+	Range *string `json:"Range,omitempty"`
+
+	// IfRange ETag или дата; Range применяется, только если изображение не изменилось
+	IfRange *string `json:"If-Range,omitempty"`
+
+	// IfNoneMatch ETag из предыдущего ответа
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
+
+	// IfModifiedSince Last-Modified из предыдущего ответа
+	IfModifiedSince *string `json:"If-Modified-Since,omitempty"`
+}
+
+// HeadStaticImageParams defines parameters for HeadStaticImage.
+type HeadStaticImageParams struct {
+	// Expires Срок действия подписанной ссылки, Unix‑время
+	Expires *int64 `form:"expires,omitempty" json:"expires,omitempty"`
+
+	// Aud Получатель, для которого выдана ссылка
+	Aud *string `form:"aud,omitempty" json:"aud,omitempty"`
+
+	// Sig HMAC‑подпись ссылки (при IMAGE_URL_SIGNING_KEY)
+	Sig *string `form:"sig,omitempty" json:"sig,omitempty"`
+}
+
+// CreateImageJobParams defines parameters for CreateImageJob.
+type CreateImageJobParams struct {
+	// Callback URL notified with the job (event job.completed) when it finishes; must be allowed by WEBHOOK_ALLOWED_HOSTS
 	Callback *string `form:"callback,omitempty" json:"callback,omitempty"`
 }
 
+// CreateWebhookSubscriptionJSONRequestBody defines body for CreateWebhookSubscription for application/json ContentType.
+type CreateWebhookSubscriptionJSONRequestBody = WebhookSubscriptionRequest
+
+// CreateBatchJSONRequestBody defines body for CreateBatch for application/json ContentType.
+type CreateBatchJSONRequestBody = BatchRequest
+
 // ChatImageMultipartRequestBody defines body for ChatImage for multipart/form-data ContentType.
 type ChatImageMultipartRequestBody = ChatImageRequest
 
 // RespondTextJSONRequestBody defines body for RespondText for application/json ContentType.
 type RespondTextJSONRequestBody = TextRequest
 
+// CreateImageJobMultipartRequestBody defines body for CreateImageJob for multipart/form-data ContentType.
+type CreateImageJobMultipartRequestBody = ChatImageRequest
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Recent webhook deliveries
+	// (GET /api/v1/admin/webhooks/deliveries)
+	ListWebhookDeliveries(ctx echo.Context, params ListWebhookDeliveriesParams) error
+	// Webhook delivery from the log
+	// (GET /api/v1/admin/webhooks/deliveries/{id})
+	GetWebhookDelivery(ctx echo.Context, id openapi_types.UUID) error
+	// Registered webhook subscriptions
+	// (GET /api/v1/admin/webhooks/subscriptions)
+	ListWebhookSubscriptions(ctx echo.Context) error
+	// Subscribe a URL to image lifecycle events
+	// (POST /api/v1/admin/webhooks/subscriptions)
+	CreateWebhookSubscription(ctx echo.Context) error
+	// Remove a webhook subscription
+	// (DELETE /api/v1/admin/webhooks/subscriptions/{id})
+	DeleteWebhookSubscription(ctx echo.Context, id openapi_types.UUID) error
+	// Submit text prompts for batch analysis
+	// (POST /api/v1/batches)
+	CreateBatch(ctx echo.Context) error
+	// Progress and results of a batch
+	// (GET /api/v1/batches/{id})
+	GetBatch(ctx echo.Context, id openapi_types.UUID) error
 	// Respond to an uploaded image containing text
 	// (POST /api/v1/chat/image)
 	ChatImage(ctx echo.Context) error
@@ -77,6 +545,27 @@ type ServerInterface interface {
 	// Retrieve a generated or stored image
 	// (GET /api/v1/images/{id})
 	GetStaticImage(ctx echo.Context, id openapi_types.UUID, params GetStaticImageParams) error
+	// Check a stored image without consuming a read
+	// (HEAD /api/v1/images/{id})
+	HeadStaticImage(ctx echo.Context, id openapi_types.UUID, params HeadStaticImageParams) error
+	// Queue analysis of uploaded images
+	// (POST /api/v1/jobs/image)
+	CreateImageJob(ctx echo.Context, params CreateImageJobParams) error
+	// Status and result of a job
+	// (GET /api/v1/jobs/{id})
+	GetJob(ctx echo.Context, id openapi_types.UUID) error
+	// Model providers with circuit breaker state and fallback routes
+	// (GET /api/v1/providers)
+	ListProviders(ctx echo.Context) error
+	// Start a multi-turn conversation session
+	// (POST /api/v1/sessions)
+	CreateSession(ctx echo.Context) error
+	// Delete a session with its history
+	// (DELETE /api/v1/sessions/{id})
+	DeleteSession(ctx echo.Context, id openapi_types.UUID) error
+	// List the history of a session
+	// (GET /api/v1/sessions/{id}/messages)
+	ListSessionMessages(ctx echo.Context, id openapi_types.UUID) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -84,6 +573,116 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
+// ListWebhookDeliveries converts echo context to params.
+func (w *ServerInterfaceWrapper) ListWebhookDeliveries(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListWebhookDeliveriesParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListWebhookDeliveries(ctx, params)
+	return err
+}
+
+// GetWebhookDelivery converts echo context to params.
+func (w *ServerInterfaceWrapper) GetWebhookDelivery(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetWebhookDelivery(ctx, id)
+	return err
+}
+
+// ListWebhookSubscriptions converts echo context to params.
+func (w *ServerInterfaceWrapper) ListWebhookSubscriptions(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListWebhookSubscriptions(ctx)
+	return err
+}
+
+// CreateWebhookSubscription converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateWebhookSubscription(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreateWebhookSubscription(ctx)
+	return err
+}
+
+// DeleteWebhookSubscription converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteWebhookSubscription(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteWebhookSubscription(ctx, id)
+	return err
+}
+
+// CreateBatch converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateBatch(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreateBatch(ctx)
+	return err
+}
+
+// GetBatch converts echo context to params.
+func (w *ServerInterfaceWrapper) GetBatch(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetBatch(ctx, id)
+	return err
+}
+
 // ChatImage converts echo context to params.
 func (w *ServerInterfaceWrapper) ChatImage(ctx echo.Context) error {
 	var err error
@@ -122,203 +721,1510 @@ func (w *ServerInterfaceWrapper) GetStaticImage(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter callback: %s", err))
 	}
 
-	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.GetStaticImage(ctx, id, params)
-	return err
-}
-
-// This is a simple interface which specifies echo.Route addition functions which
-// are present on both echo.Echo and echo.Group, since we want to allow using
-// either of them for path registration
-type EchoRouter interface {
-	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
-}
+	// ------------- Optional query parameter "expires" -------------
 
-// RegisterHandlers adds each server route to the EchoRouter.
-func RegisterHandlers(router EchoRouter, si ServerInterface) {
-	RegisterHandlersWithBaseURL(router, si, "")
-}
+	err = runtime.BindQueryParameter("form", true, false, "expires", ctx.QueryParams(), &params.Expires)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter expires: %s", err))
+	}
 
-// Registers handlers, and prepends BaseURL to the paths, so that the paths
-// can be served under a prefix.
-func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+	// ------------- Optional query parameter "aud" -------------
 
-	wrapper := ServerInterfaceWrapper{
-		Handler: si,
+	err = runtime.BindQueryParameter("form", true, false, "aud", ctx.QueryParams(), &params.Aud)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter aud: %s", err))
 	}
 
-	router.POST(baseURL+"/api/v1/chat/image", wrapper.ChatImage)
-	router.POST(baseURL+"/api/v1/chat/text", wrapper.RespondText)
-	router.GET(baseURL+"/api/v1/images/:id", wrapper.GetStaticImage)
+	// ------------- Optional query parameter "sig" -------------
 
-}
+	err = runtime.BindQueryParameter("form", true, false, "sig", ctx.QueryParams(), &params.Sig)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sig: %s", err))
+	}
 
-type ChatImageRequestObject struct {
-	Body *multipart.Reader
-}
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Range" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Range")]; found {
+		var Range string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Range, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Range", valueList[0], &Range, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Range: %s", err))
+		}
+
+		params.Range = &Range
+	}
+	// ------------- Optional header parameter "If-Range" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Range")]; found {
+		var IfRange string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Range, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Range", valueList[0], &IfRange, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Range: %s", err))
+		}
+
+		params.IfRange = &IfRange
+	}
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+	// ------------- Optional header parameter "If-Modified-Since" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Modified-Since")]; found {
+		var IfModifiedSince string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Modified-Since, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Modified-Since", valueList[0], &IfModifiedSince, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Modified-Since: %s", err))
+		}
+
+		params.IfModifiedSince = &IfModifiedSince
+	}
 
-type ChatImageResponseObject interface {
-	VisitChatImageResponse(w http.ResponseWriter) error
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetStaticImage(ctx, id, params)
+	return err
 }
 
-type ChatImage200JSONResponse CommonResponse
+// HeadStaticImage converts echo context to params.
+func (w *ServerInterfaceWrapper) HeadStaticImage(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
 
-func (response ChatImage200JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params HeadStaticImageParams
+	// ------------- Optional query parameter "expires" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "expires", ctx.QueryParams(), &params.Expires)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter expires: %s", err))
+	}
+
+	// ------------- Optional query parameter "aud" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "aud", ctx.QueryParams(), &params.Aud)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter aud: %s", err))
+	}
+
+	// ------------- Optional query parameter "sig" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sig", ctx.QueryParams(), &params.Sig)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sig: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.HeadStaticImage(ctx, id, params)
+	return err
+}
+
+// CreateImageJob converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateImageJob(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreateImageJobParams
+	// ------------- Optional query parameter "callback" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "callback", ctx.QueryParams(), &params.Callback)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter callback: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreateImageJob(ctx, params)
+	return err
+}
+
+// GetJob converts echo context to params.
+func (w *ServerInterfaceWrapper) GetJob(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetJob(ctx, id)
+	return err
+}
+
+// ListProviders converts echo context to params.
+func (w *ServerInterfaceWrapper) ListProviders(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListProviders(ctx)
+	return err
+}
+
+// CreateSession converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateSession(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreateSession(ctx)
+	return err
+}
+
+// DeleteSession converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteSession(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteSession(ctx, id)
+	return err
+}
+
+// ListSessionMessages converts echo context to params.
+func (w *ServerInterfaceWrapper) ListSessionMessages(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListSessionMessages(ctx, id)
+	return err
+}
+
+// This is a simple interface which specifies echo.Route addition functions which
+// are present on both echo.Echo and echo.Group, since we want to allow using
+// either of them for path registration
+type EchoRouter interface {
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers adds each server route to the EchoRouter.
+func RegisterHandlers(router EchoRouter, si ServerInterface) {
+	RegisterHandlersWithBaseURL(router, si, "")
+}
+
+// Registers handlers, and prepends BaseURL to the paths, so that the paths
+// can be served under a prefix.
+func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	router.GET(baseURL+"/api/v1/admin/webhooks/deliveries", wrapper.ListWebhookDeliveries)
+	router.GET(baseURL+"/api/v1/admin/webhooks/deliveries/:id", wrapper.GetWebhookDelivery)
+	router.GET(baseURL+"/api/v1/admin/webhooks/subscriptions", wrapper.ListWebhookSubscriptions)
+	router.POST(baseURL+"/api/v1/admin/webhooks/subscriptions", wrapper.CreateWebhookSubscription)
+	router.DELETE(baseURL+"/api/v1/admin/webhooks/subscriptions/:id", wrapper.DeleteWebhookSubscription)
+	router.POST(baseURL+"/api/v1/batches", wrapper.CreateBatch)
+	router.GET(baseURL+"/api/v1/batches/:id", wrapper.GetBatch)
+	router.POST(baseURL+"/api/v1/chat/image", wrapper.ChatImage)
+	router.POST(baseURL+"/api/v1/chat/text", wrapper.RespondText)
+	router.GET(baseURL+"/api/v1/images/:id", wrapper.GetStaticImage)
+	router.HEAD(baseURL+"/api/v1/images/:id", wrapper.HeadStaticImage)
+	router.POST(baseURL+"/api/v1/jobs/image", wrapper.CreateImageJob)
+	router.GET(baseURL+"/api/v1/jobs/:id", wrapper.GetJob)
+	router.GET(baseURL+"/api/v1/providers", wrapper.ListProviders)
+	router.POST(baseURL+"/api/v1/sessions", wrapper.CreateSession)
+	router.DELETE(baseURL+"/api/v1/sessions/:id", wrapper.DeleteSession)
+	router.GET(baseURL+"/api/v1/sessions/:id/messages", wrapper.ListSessionMessages)
+
+}
+
+type ListWebhookDeliveriesRequestObject struct {
+	Params ListWebhookDeliveriesParams
+}
+
+type ListWebhookDeliveriesResponseObject interface {
+	VisitListWebhookDeliveriesResponse(w http.ResponseWriter) error
+}
+
+type ListWebhookDeliveries200JSONResponse WebhookDeliveryList
+
+func (response ListWebhookDeliveries200JSONResponse) VisitListWebhookDeliveriesResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type ChatImage400JSONResponse ErrorResponse
+type ListWebhookDeliveries400JSONResponse ErrorResponse
 
-func (response ChatImage400JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+func (response ListWebhookDeliveries400JSONResponse) VisitListWebhookDeliveriesResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(400)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type ChatImage500JSONResponse ErrorResponse
+type ListWebhookDeliveries401JSONResponse ErrorResponse
 
-func (response ChatImage500JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+func (response ListWebhookDeliveries401JSONResponse) VisitListWebhookDeliveriesResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(500)
+	w.WriteHeader(401)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type RespondTextRequestObject struct {
-	Body *RespondTextJSONRequestBody
+type GetWebhookDeliveryRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
 }
 
-type RespondTextResponseObject interface {
-	VisitRespondTextResponse(w http.ResponseWriter) error
+type GetWebhookDeliveryResponseObject interface {
+	VisitGetWebhookDeliveryResponse(w http.ResponseWriter) error
 }
 
-type RespondText200JSONResponse CommonResponse
+type GetWebhookDelivery200JSONResponse WebhookDelivery
 
-func (response RespondText200JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+func (response GetWebhookDelivery200JSONResponse) VisitGetWebhookDeliveryResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type RespondText400JSONResponse ErrorResponse
+type GetWebhookDelivery401JSONResponse ErrorResponse
 
-func (response RespondText400JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+func (response GetWebhookDelivery401JSONResponse) VisitGetWebhookDeliveryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetWebhookDelivery404JSONResponse ErrorResponse
+
+func (response GetWebhookDelivery404JSONResponse) VisitGetWebhookDeliveryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListWebhookSubscriptionsRequestObject struct {
+}
+
+type ListWebhookSubscriptionsResponseObject interface {
+	VisitListWebhookSubscriptionsResponse(w http.ResponseWriter) error
+}
+
+type ListWebhookSubscriptions200JSONResponse WebhookSubscriptionList
+
+func (response ListWebhookSubscriptions200JSONResponse) VisitListWebhookSubscriptionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListWebhookSubscriptions401JSONResponse ErrorResponse
+
+func (response ListWebhookSubscriptions401JSONResponse) VisitListWebhookSubscriptionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateWebhookSubscriptionRequestObject struct {
+	Body *CreateWebhookSubscriptionJSONRequestBody
+}
+
+type CreateWebhookSubscriptionResponseObject interface {
+	VisitCreateWebhookSubscriptionResponse(w http.ResponseWriter) error
+}
+
+type CreateWebhookSubscription201JSONResponse WebhookSubscription
+
+func (response CreateWebhookSubscription201JSONResponse) VisitCreateWebhookSubscriptionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateWebhookSubscription400JSONResponse ErrorResponse
+
+func (response CreateWebhookSubscription400JSONResponse) VisitCreateWebhookSubscriptionResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(400)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type RespondText500JSONResponse ErrorResponse
+type CreateWebhookSubscription401JSONResponse ErrorResponse
 
-func (response RespondText500JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+func (response CreateWebhookSubscription401JSONResponse) VisitCreateWebhookSubscriptionResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(500)
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteWebhookSubscriptionRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type DeleteWebhookSubscriptionResponseObject interface {
+	VisitDeleteWebhookSubscriptionResponse(w http.ResponseWriter) error
+}
+
+type DeleteWebhookSubscription204Response struct {
+}
+
+func (response DeleteWebhookSubscription204Response) VisitDeleteWebhookSubscriptionResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type DeleteWebhookSubscription401JSONResponse ErrorResponse
+
+func (response DeleteWebhookSubscription401JSONResponse) VisitDeleteWebhookSubscriptionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteWebhookSubscription404JSONResponse ErrorResponse
+
+func (response DeleteWebhookSubscription404JSONResponse) VisitDeleteWebhookSubscriptionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateBatchRequestObject struct {
+	Body *CreateBatchJSONRequestBody
+}
+
+type CreateBatchResponseObject interface {
+	VisitCreateBatchResponse(w http.ResponseWriter) error
+}
+
+type CreateBatch202ResponseHeaders struct {
+	Location string
+}
+
+type CreateBatch202JSONResponse struct {
+	Body    Batch
+	Headers CreateBatch202ResponseHeaders
+}
+
+func (response CreateBatch202JSONResponse) VisitCreateBatchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprint(response.Headers.Location))
+	w.WriteHeader(202)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CreateBatch400JSONResponse ErrorResponse
+
+func (response CreateBatch400JSONResponse) VisitCreateBatchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateBatch502JSONResponse ErrorResponse
+
+func (response CreateBatch502JSONResponse) VisitCreateBatchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateBatch503JSONResponse ErrorResponse
+
+func (response CreateBatch503JSONResponse) VisitCreateBatchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetBatchRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type GetBatchResponseObject interface {
+	VisitGetBatchResponse(w http.ResponseWriter) error
+}
+
+type GetBatch200JSONResponse Batch
+
+func (response GetBatch200JSONResponse) VisitGetBatchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetBatch404JSONResponse ErrorResponse
+
+func (response GetBatch404JSONResponse) VisitGetBatchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChatImageRequestObject struct {
+	Body *multipart.Reader
+}
+
+type ChatImageResponseObject interface {
+	VisitChatImageResponse(w http.ResponseWriter) error
+}
+
+type ChatImage200JSONResponse CommonResponse
+
+func (response ChatImage200JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChatImage400JSONResponse ErrorResponse
+
+func (response ChatImage400JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChatImage413JSONResponse ErrorResponse
+
+func (response ChatImage413JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(413)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChatImage499JSONResponse ErrorResponse
+
+func (response ChatImage499JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(499)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChatImage500JSONResponse ErrorResponse
+
+func (response ChatImage500JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChatImage502JSONResponse ErrorResponse
+
+func (response ChatImage502JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChatImage503JSONResponse ErrorResponse
+
+func (response ChatImage503JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChatImage504JSONResponse ErrorResponse
+
+func (response ChatImage504JSONResponse) VisitChatImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(504)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RespondTextRequestObject struct {
+	Body *RespondTextJSONRequestBody
+}
+
+type RespondTextResponseObject interface {
+	VisitRespondTextResponse(w http.ResponseWriter) error
+}
+
+type RespondText200JSONResponse CommonResponse
+
+func (response RespondText200JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RespondText200TexteventStreamResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response RespondText200TexteventStreamResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type RespondText400JSONResponse ErrorResponse
+
+func (response RespondText400JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RespondText404JSONResponse ErrorResponse
+
+func (response RespondText404JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RespondText499JSONResponse ErrorResponse
+
+func (response RespondText499JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(499)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RespondText500JSONResponse ErrorResponse
+
+func (response RespondText500JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RespondText502JSONResponse ErrorResponse
+
+func (response RespondText502JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RespondText504JSONResponse ErrorResponse
+
+func (response RespondText504JSONResponse) VisitRespondTextResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(504)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetStaticImageRequestObject struct {
+	Id     openapi_types.UUID `json:"id"`
+	Params GetStaticImageParams
+}
+
+type GetStaticImageResponseObject interface {
+	VisitGetStaticImageResponse(w http.ResponseWriter) error
+}
+
+type GetStaticImage200ResponseHeaders struct {
+	AcceptRanges string
+	ETag         string
+	Expires      string
+	LastModified string
+}
+
+type GetStaticImage200ImagegifResponse struct {
+	Body          io.Reader
+	Headers       GetStaticImage200ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetStaticImage200ImagegifResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/gif")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetStaticImage200ImagejpegResponse struct {
+	Body          io.Reader
+	Headers       GetStaticImage200ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetStaticImage200ImagejpegResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/jpeg")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetStaticImage200ImagepngResponse struct {
+	Body          io.Reader
+	Headers       GetStaticImage200ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetStaticImage200ImagepngResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/png")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetStaticImage200ImagewebpResponse struct {
+	Body          io.Reader
+	Headers       GetStaticImage200ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetStaticImage200ImagewebpResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/webp")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetStaticImage206ResponseHeaders struct {
+	AcceptRanges string
+	ContentRange string
+	ETag         string
+	Expires      string
+	LastModified string
+}
+
+type GetStaticImage206ImagegifResponse struct {
+	Body          io.Reader
+	Headers       GetStaticImage206ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetStaticImage206ImagegifResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/gif")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(206)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetStaticImage206ImagejpegResponse struct {
+	Body          io.Reader
+	Headers       GetStaticImage206ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetStaticImage206ImagejpegResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/jpeg")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(206)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetStaticImage206ImagepngResponse struct {
+	Body          io.Reader
+	Headers       GetStaticImage206ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetStaticImage206ImagepngResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/png")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(206)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetStaticImage206ImagewebpResponse struct {
+	Body          io.Reader
+	Headers       GetStaticImage206ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetStaticImage206ImagewebpResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/webp")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(206)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetStaticImage304ResponseHeaders struct {
+	ETag         string
+	Expires      string
+	LastModified string
+}
+
+type GetStaticImage304Response struct {
+	Headers GetStaticImage304ResponseHeaders
+}
+
+func (response GetStaticImage304Response) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(304)
+	return nil
+}
+
+type GetStaticImage400JSONResponse ErrorResponse
+
+func (response GetStaticImage400JSONResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetStaticImage403JSONResponse ErrorResponse
+
+func (response GetStaticImage403JSONResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetStaticImage404JSONResponse ErrorResponse
+
+func (response GetStaticImage404JSONResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetStaticImage416ResponseHeaders struct {
+	ContentRange string
+}
+
+type GetStaticImage416Response struct {
+	Headers GetStaticImage416ResponseHeaders
+}
+
+func (response GetStaticImage416Response) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	w.WriteHeader(416)
+	return nil
+}
+
+type GetStaticImage500JSONResponse ErrorResponse
+
+func (response GetStaticImage500JSONResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type HeadStaticImageRequestObject struct {
+	Id     openapi_types.UUID `json:"id"`
+	Params HeadStaticImageParams
+}
+
+type HeadStaticImageResponseObject interface {
+	VisitHeadStaticImageResponse(w http.ResponseWriter) error
+}
+
+type HeadStaticImage200ResponseHeaders struct {
+	AcceptRanges string
+	ETag         string
+	Expires      string
+	LastModified string
+}
+
+type HeadStaticImage200ImagegifResponse struct {
+	Body          io.Reader
+	Headers       HeadStaticImage200ResponseHeaders
+	ContentLength int64
+}
+
+func (response HeadStaticImage200ImagegifResponse) VisitHeadStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/gif")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type HeadStaticImage200ImagejpegResponse struct {
+	Body          io.Reader
+	Headers       HeadStaticImage200ResponseHeaders
+	ContentLength int64
+}
+
+func (response HeadStaticImage200ImagejpegResponse) VisitHeadStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/jpeg")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type HeadStaticImage200ImagepngResponse struct {
+	Body          io.Reader
+	Headers       HeadStaticImage200ResponseHeaders
+	ContentLength int64
+}
+
+func (response HeadStaticImage200ImagepngResponse) VisitHeadStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/png")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type HeadStaticImage200ImagewebpResponse struct {
+	Body          io.Reader
+	Headers       HeadStaticImage200ResponseHeaders
+	ContentLength int64
+}
+
+func (response HeadStaticImage200ImagewebpResponse) VisitHeadStaticImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/webp")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Expires", fmt.Sprint(response.Headers.Expires))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type HeadStaticImage403Response struct {
+}
+
+func (response HeadStaticImage403Response) VisitHeadStaticImageResponse(w http.ResponseWriter) error {
+	w.WriteHeader(403)
+	return nil
+}
+
+type HeadStaticImage404Response struct {
+}
+
+func (response HeadStaticImage404Response) VisitHeadStaticImageResponse(w http.ResponseWriter) error {
+	w.WriteHeader(404)
+	return nil
+}
+
+type CreateImageJobRequestObject struct {
+	Params CreateImageJobParams
+	Body   *multipart.Reader
+}
+
+type CreateImageJobResponseObject interface {
+	VisitCreateImageJobResponse(w http.ResponseWriter) error
+}
+
+type CreateImageJob202ResponseHeaders struct {
+	Location string
+}
+
+type CreateImageJob202JSONResponse struct {
+	Body    Job
+	Headers CreateImageJob202ResponseHeaders
+}
+
+func (response CreateImageJob202JSONResponse) VisitCreateImageJobResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprint(response.Headers.Location))
+	w.WriteHeader(202)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CreateImageJob400JSONResponse ErrorResponse
+
+func (response CreateImageJob400JSONResponse) VisitCreateImageJobResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateImageJob413JSONResponse ErrorResponse
+
+func (response CreateImageJob413JSONResponse) VisitCreateImageJobResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(413)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateImageJob503JSONResponse ErrorResponse
+
+func (response CreateImageJob503JSONResponse) VisitCreateImageJobResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetJobRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type GetJobResponseObject interface {
+	VisitGetJobResponse(w http.ResponseWriter) error
+}
+
+type GetJob200JSONResponse Job
+
+func (response GetJob200JSONResponse) VisitGetJobResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetJob404JSONResponse ErrorResponse
+
+func (response GetJob404JSONResponse) VisitGetJobResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListProvidersRequestObject struct {
+}
+
+type ListProvidersResponseObject interface {
+	VisitListProvidersResponse(w http.ResponseWriter) error
+}
+
+type ListProviders200JSONResponse ProvidersStatus
+
+func (response ListProviders200JSONResponse) VisitListProvidersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateSessionRequestObject struct {
+}
+
+type CreateSessionResponseObject interface {
+	VisitCreateSessionResponse(w http.ResponseWriter) error
+}
+
+type CreateSession201JSONResponse SessionResponse
+
+func (response CreateSession201JSONResponse) VisitCreateSessionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateSession500JSONResponse ErrorResponse
+
+func (response CreateSession500JSONResponse) VisitCreateSessionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteSessionRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type DeleteSessionResponseObject interface {
+	VisitDeleteSessionResponse(w http.ResponseWriter) error
+}
+
+type DeleteSession204Response struct {
+}
+
+func (response DeleteSession204Response) VisitDeleteSessionResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type DeleteSession404JSONResponse ErrorResponse
+
+func (response DeleteSession404JSONResponse) VisitDeleteSessionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListSessionMessagesRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type ListSessionMessagesResponseObject interface {
+	VisitListSessionMessagesResponse(w http.ResponseWriter) error
+}
+
+type ListSessionMessages200JSONResponse SessionHistory
+
+func (response ListSessionMessages200JSONResponse) VisitListSessionMessagesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListSessionMessages404JSONResponse ErrorResponse
+
+func (response ListSessionMessages404JSONResponse) VisitListSessionMessagesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// Recent webhook deliveries
+	// (GET /api/v1/admin/webhooks/deliveries)
+	ListWebhookDeliveries(ctx context.Context, request ListWebhookDeliveriesRequestObject) (ListWebhookDeliveriesResponseObject, error)
+	// Webhook delivery from the log
+	// (GET /api/v1/admin/webhooks/deliveries/{id})
+	GetWebhookDelivery(ctx context.Context, request GetWebhookDeliveryRequestObject) (GetWebhookDeliveryResponseObject, error)
+	// Registered webhook subscriptions
+	// (GET /api/v1/admin/webhooks/subscriptions)
+	ListWebhookSubscriptions(ctx context.Context, request ListWebhookSubscriptionsRequestObject) (ListWebhookSubscriptionsResponseObject, error)
+	// Subscribe a URL to image lifecycle events
+	// (POST /api/v1/admin/webhooks/subscriptions)
+	CreateWebhookSubscription(ctx context.Context, request CreateWebhookSubscriptionRequestObject) (CreateWebhookSubscriptionResponseObject, error)
+	// Remove a webhook subscription
+	// (DELETE /api/v1/admin/webhooks/subscriptions/{id})
+	DeleteWebhookSubscription(ctx context.Context, request DeleteWebhookSubscriptionRequestObject) (DeleteWebhookSubscriptionResponseObject, error)
+	// Submit text prompts for batch analysis
+	// (POST /api/v1/batches)
+	CreateBatch(ctx context.Context, request CreateBatchRequestObject) (CreateBatchResponseObject, error)
+	// Progress and results of a batch
+	// (GET /api/v1/batches/{id})
+	GetBatch(ctx context.Context, request GetBatchRequestObject) (GetBatchResponseObject, error)
+	// Respond to an uploaded image containing text
+	// (POST /api/v1/chat/image)
+	ChatImage(ctx context.Context, request ChatImageRequestObject) (ChatImageResponseObject, error)
+	// Respond to text input
+	// (POST /api/v1/chat/text)
+	RespondText(ctx context.Context, request RespondTextRequestObject) (RespondTextResponseObject, error)
+	// Retrieve a generated or stored image
+	// (GET /api/v1/images/{id})
+	GetStaticImage(ctx context.Context, request GetStaticImageRequestObject) (GetStaticImageResponseObject, error)
+	// Check a stored image without consuming a read
+	// (HEAD /api/v1/images/{id})
+	HeadStaticImage(ctx context.Context, request HeadStaticImageRequestObject) (HeadStaticImageResponseObject, error)
+	// Queue analysis of uploaded images
+	// (POST /api/v1/jobs/image)
+	CreateImageJob(ctx context.Context, request CreateImageJobRequestObject) (CreateImageJobResponseObject, error)
+	// Status and result of a job
+	// (GET /api/v1/jobs/{id})
+	GetJob(ctx context.Context, request GetJobRequestObject) (GetJobResponseObject, error)
+	// Model providers with circuit breaker state and fallback routes
+	// (GET /api/v1/providers)
+	ListProviders(ctx context.Context, request ListProvidersRequestObject) (ListProvidersResponseObject, error)
+	// Start a multi-turn conversation session
+	// (POST /api/v1/sessions)
+	CreateSession(ctx context.Context, request CreateSessionRequestObject) (CreateSessionResponseObject, error)
+	// Delete a session with its history
+	// (DELETE /api/v1/sessions/{id})
+	DeleteSession(ctx context.Context, request DeleteSessionRequestObject) (DeleteSessionResponseObject, error)
+	// List the history of a session
+	// (GET /api/v1/sessions/{id}/messages)
+	ListSessionMessages(ctx context.Context, request ListSessionMessagesRequestObject) (ListSessionMessagesResponseObject, error)
+}
+
+type StrictHandlerFunc = strictecho.StrictEchoHandlerFunc
+type StrictMiddlewareFunc = strictecho.StrictEchoMiddlewareFunc
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+}
+
+// ListWebhookDeliveries operation middleware
+func (sh *strictHandler) ListWebhookDeliveries(ctx echo.Context, params ListWebhookDeliveriesParams) error {
+	var request ListWebhookDeliveriesRequestObject
+
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListWebhookDeliveries(ctx.Request().Context(), request.(ListWebhookDeliveriesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListWebhookDeliveries")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListWebhookDeliveriesResponseObject); ok {
+		return validResponse.VisitListWebhookDeliveriesResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetWebhookDelivery operation middleware
+func (sh *strictHandler) GetWebhookDelivery(ctx echo.Context, id openapi_types.UUID) error {
+	var request GetWebhookDeliveryRequestObject
+
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetWebhookDelivery(ctx.Request().Context(), request.(GetWebhookDeliveryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetWebhookDelivery")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetWebhookDeliveryResponseObject); ok {
+		return validResponse.VisitGetWebhookDeliveryResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListWebhookSubscriptions operation middleware
+func (sh *strictHandler) ListWebhookSubscriptions(ctx echo.Context) error {
+	var request ListWebhookSubscriptionsRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListWebhookSubscriptions(ctx.Request().Context(), request.(ListWebhookSubscriptionsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListWebhookSubscriptions")
+	}
 
-	return json.NewEncoder(w).Encode(response)
-}
+	response, err := handler(ctx, request)
 
-type GetStaticImageRequestObject struct {
-	Id     openapi_types.UUID `json:"id"`
-	Params GetStaticImageParams
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListWebhookSubscriptionsResponseObject); ok {
+		return validResponse.VisitListWebhookSubscriptionsResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type GetStaticImageResponseObject interface {
-	VisitGetStaticImageResponse(w http.ResponseWriter) error
-}
+// CreateWebhookSubscription operation middleware
+func (sh *strictHandler) CreateWebhookSubscription(ctx echo.Context) error {
+	var request CreateWebhookSubscriptionRequestObject
 
-type GetStaticImage200ImagejpegResponse struct {
-	Body          io.Reader
-	ContentLength int64
-}
+	var body CreateWebhookSubscriptionJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return err
+	}
+	request.Body = &body
 
-func (response GetStaticImage200ImagejpegResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
-	w.Header().Set("Content-Type", "image/jpeg")
-	if response.ContentLength != 0 {
-		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateWebhookSubscription(ctx.Request().Context(), request.(CreateWebhookSubscriptionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateWebhookSubscription")
 	}
-	w.WriteHeader(200)
 
-	if closer, ok := response.Body.(io.ReadCloser); ok {
-		defer closer.Close()
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(CreateWebhookSubscriptionResponseObject); ok {
+		return validResponse.VisitCreateWebhookSubscriptionResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
 	}
-	_, err := io.Copy(w, response.Body)
-	return err
+	return nil
 }
 
-type GetStaticImage200ImagepngResponse struct {
-	Body          io.Reader
-	ContentLength int64
-}
+// DeleteWebhookSubscription operation middleware
+func (sh *strictHandler) DeleteWebhookSubscription(ctx echo.Context, id openapi_types.UUID) error {
+	var request DeleteWebhookSubscriptionRequestObject
 
-func (response GetStaticImage200ImagepngResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
-	w.Header().Set("Content-Type", "image/png")
-	if response.ContentLength != 0 {
-		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteWebhookSubscription(ctx.Request().Context(), request.(DeleteWebhookSubscriptionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteWebhookSubscription")
 	}
-	w.WriteHeader(200)
 
-	if closer, ok := response.Body.(io.ReadCloser); ok {
-		defer closer.Close()
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(DeleteWebhookSubscriptionResponseObject); ok {
+		return validResponse.VisitDeleteWebhookSubscriptionResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
 	}
-	_, err := io.Copy(w, response.Body)
-	return err
+	return nil
 }
 
-type GetStaticImage404JSONResponse ErrorResponse
-
-func (response GetStaticImage404JSONResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(404)
+// CreateBatch operation middleware
+func (sh *strictHandler) CreateBatch(ctx echo.Context) error {
+	var request CreateBatchRequestObject
 
-	return json.NewEncoder(w).Encode(response)
-}
+	var body CreateBatchJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return err
+	}
+	request.Body = &body
 
-type GetStaticImage500JSONResponse ErrorResponse
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateBatch(ctx.Request().Context(), request.(CreateBatchRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateBatch")
+	}
 
-func (response GetStaticImage500JSONResponse) VisitGetStaticImageResponse(w http.ResponseWriter) error {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(500)
+	response, err := handler(ctx, request)
 
-	return json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(CreateBatchResponseObject); ok {
+		return validResponse.VisitCreateBatchResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-// StrictServerInterface represents all server handlers.
-type StrictServerInterface interface {
-	// Respond to an uploaded image containing text
-	// (POST /api/v1/chat/image)
-	ChatImage(ctx context.Context, request ChatImageRequestObject) (ChatImageResponseObject, error)
-	// Respond to text input
-	// (POST /api/v1/chat/text)
-	RespondText(ctx context.Context, request RespondTextRequestObject) (RespondTextResponseObject, error)
-	// Retrieve a generated or stored image
-	// (GET /api/v1/images/{id})
-	GetStaticImage(ctx context.Context, request GetStaticImageRequestObject) (GetStaticImageResponseObject, error)
-}
+// GetBatch operation middleware
+func (sh *strictHandler) GetBatch(ctx echo.Context, id openapi_types.UUID) error {
+	var request GetBatchRequestObject
 
-type StrictHandlerFunc = strictecho.StrictEchoHandlerFunc
-type StrictMiddlewareFunc = strictecho.StrictEchoMiddlewareFunc
+	request.Id = id
 
-func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
-	return &strictHandler{ssi: ssi, middlewares: middlewares}
-}
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBatch(ctx.Request().Context(), request.(GetBatchRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBatch")
+	}
 
-type strictHandler struct {
-	ssi         StrictServerInterface
-	middlewares []StrictMiddlewareFunc
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetBatchResponseObject); ok {
+		return validResponse.VisitGetBatchResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
 // ChatImage operation middleware
@@ -404,3 +2310,181 @@ func (sh *strictHandler) GetStaticImage(ctx echo.Context, id openapi_types.UUID,
 	}
 	return nil
 }
+
+// HeadStaticImage operation middleware
+func (sh *strictHandler) HeadStaticImage(ctx echo.Context, id openapi_types.UUID, params HeadStaticImageParams) error {
+	var request HeadStaticImageRequestObject
+
+	request.Id = id
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.HeadStaticImage(ctx.Request().Context(), request.(HeadStaticImageRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "HeadStaticImage")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(HeadStaticImageResponseObject); ok {
+		return validResponse.VisitHeadStaticImageResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateImageJob operation middleware
+func (sh *strictHandler) CreateImageJob(ctx echo.Context, params CreateImageJobParams) error {
+	var request CreateImageJobRequestObject
+
+	request.Params = params
+
+	if reader, err := ctx.Request().MultipartReader(); err != nil {
+		return err
+	} else {
+		request.Body = reader
+	}
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateImageJob(ctx.Request().Context(), request.(CreateImageJobRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateImageJob")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(CreateImageJobResponseObject); ok {
+		return validResponse.VisitCreateImageJobResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetJob operation middleware
+func (sh *strictHandler) GetJob(ctx echo.Context, id openapi_types.UUID) error {
+	var request GetJobRequestObject
+
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetJob(ctx.Request().Context(), request.(GetJobRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetJob")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetJobResponseObject); ok {
+		return validResponse.VisitGetJobResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListProviders operation middleware
+func (sh *strictHandler) ListProviders(ctx echo.Context) error {
+	var request ListProvidersRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListProviders(ctx.Request().Context(), request.(ListProvidersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListProviders")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListProvidersResponseObject); ok {
+		return validResponse.VisitListProvidersResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateSession operation middleware
+func (sh *strictHandler) CreateSession(ctx echo.Context) error {
+	var request CreateSessionRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateSession(ctx.Request().Context(), request.(CreateSessionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateSession")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(CreateSessionResponseObject); ok {
+		return validResponse.VisitCreateSessionResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// DeleteSession operation middleware
+func (sh *strictHandler) DeleteSession(ctx echo.Context, id openapi_types.UUID) error {
+	var request DeleteSessionRequestObject
+
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteSession(ctx.Request().Context(), request.(DeleteSessionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteSession")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(DeleteSessionResponseObject); ok {
+		return validResponse.VisitDeleteSessionResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListSessionMessages operation middleware
+func (sh *strictHandler) ListSessionMessages(ctx echo.Context, id openapi_types.UUID) error {
+	var request ListSessionMessagesRequestObject
+
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListSessionMessages(ctx.Request().Context(), request.(ListSessionMessagesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListSessionMessages")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListSessionMessagesResponseObject); ok {
+		return validResponse.VisitListSessionMessagesResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package fake provides a local model backend that answers with a fixed,
+// contract-compliant set of items. It needs no credentials and is meant for
+// development and tests.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"pod_api/pkg/fashion"
+	"pod_api/pkg/models"
+	"pod_api/pkg/providers"
+)
+
+// ProviderName identifies the fake backend in configuration.
+const ProviderName = "fake"
+
+// ModelName is reported in responses.
+const ModelName = "fake-stylist"
+
+// streamChunkSize is the number of bytes per streamed chunk.
+const streamChunkSize = 32
+
+var capsule = []fashion.Item{
+	{Category: fashion.CategoryCoat, Style: fashion.StyleClassic, Fit: fashion.FitRegular, Layer: fashion.LayerOuter, Formality: fashion.FormalitySmart, Gender: fashion.GenderUnisex, Season: fashion.SeasonAutumn, Temperature: fashion.TemperatureMild, Colors: []fashion.Color{"beige"}, Materials: []fashion.Material{"wool"}},
+	{Category: fashion.CategorySweater, Style: fashion.StyleCasual, Fit: fashion.FitRegular, Layer: fashion.LayerMid, Formality: fashion.FormalityCasual, Gender: fashion.GenderUnisex, Season: fashion.SeasonAutumn, Temperature: fashion.TemperatureMild, Colors: []fashion.Color{"cream"}, Materials: []fashion.Material{"cashmere"}},
+	{Category: fashion.CategoryShirt, Style: fashion.StyleClassic, Fit: fashion.FitSlim, Layer: fashion.LayerBase, Formality: fashion.FormalitySmart, Gender: fashion.GenderUnisex, Season: fashion.SeasonAllSeasons, Temperature: fashion.TemperatureWarm, Colors: []fashion.Color{"white"}, Materials: []fashion.Material{"cotton"}},
+	{Category: fashion.CategoryJeans, Style: fashion.StyleCasual, Fit: fashion.FitRegular, Layer: fashion.LayerBase, Formality: fashion.FormalityCasual, Gender: fashion.GenderUnisex, Season: fashion.SeasonAllSeasons, Temperature: fashion.TemperatureMild, Colors: []fashion.Color{"denim"}, Materials: []fashion.Material{"denim"}},
+	{Category: fashion.CategoryBoots, Style: fashion.StyleClassic, Fit: fashion.FitRegular, Layer: fashion.LayerAccessory, Formality: fashion.FormalitySmart, Gender: fashion.GenderUnisex, Season: fashion.SeasonAutumn, Temperature: fashion.TemperatureCold, Colors: []fashion.Color{"brown"}, Materials: []fashion.Material{"leather"}},
+}
+
+// Client is a deterministic in-process model.
+type Client struct {
+	answer string
+}
+
+// NewClient constructs the fake backend.
+func NewClient() *Client {
+	answer, _ := json.Marshal(capsule)
+	return &Client{answer: string(answer)}
+}
+
+// Name implements providers.Provider.
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+// Capabilities implements providers.Provider.
+func (c *Client) Capabilities() providers.Capabilities {
+	return providers.Capabilities{
+		providers.CapabilityText,
+		providers.CapabilityVision,
+		providers.CapabilityStreaming,
+	}
+}
+
+// SendMessage implements providers.TextModel.
+func (c *Client) SendMessage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(request.Messages) == 0 {
+		return nil, errors.New("empty message")
+	}
+	return c.respond(), nil
+}
+
+// SendImage implements providers.ImageModel.
+func (c *Client) SendImage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	return c.SendMessage(ctx, request)
+}
+
+// StreamMessage implements providers.StreamingTextModel.
+func (c *Client) StreamMessage(ctx context.Context, request models.ChatRequest) (models.ChatStream, error) {
+	if len(request.Messages) == 0 {
+		return nil, errors.New("empty message")
+	}
+	return &stream{ctx: ctx, answer: c.answer}, nil
+}
+
+func (c *Client) respond() *models.ChatResponse {
+	return &models.ChatResponse{
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   ModelName,
+		Choices: []models.ChatChoice{{
+			FinishReason: "stop",
+			Message:      models.ChatMessage{Role: models.RoleAssistant, Content: c.answer},
+		}},
+		Usage: &models.ChatUsage{},
+	}
+}
+
+// stream splits the answer into fixed-size chunks.
+type stream struct {
+	ctx    context.Context
+	answer string
+	offset int
+	done   bool
+}
+
+func (s *stream) Next() (models.ChatChunk, error) {
+	if err := s.ctx.Err(); err != nil {
+		return models.ChatChunk{}, err
+	}
+	if s.done {
+		return models.ChatChunk{}, io.EOF
+	}
+	if s.offset >= len(s.answer) {
+		s.done = true
+		return models.ChatChunk{Model: ModelName, FinishReason: "stop", Usage: &models.ChatUsage{}}, nil
+	}
+	end := min(s.offset+streamChunkSize, len(s.answer))
+	chunk := models.ChatChunk{Model: ModelName, Content: s.answer[s.offset:end]}
+	s.offset = end
+	return chunk, nil
+}
+
+func (s *stream) Close() error {
+	return nil
+}
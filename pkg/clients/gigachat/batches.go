@@ -0,0 +1,162 @@
+package gigachat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	apigen "pod_api/pkg/apigen/gigachat"
+	"pod_api/pkg/models"
+)
+
+// batchLine is a request line of the JSONL batch file; ID is the request
+// index, echoed back in the output file.
+type batchLine struct {
+	ID      string      `json:"id"`
+	Request apigen.Chat `json:"request"`
+}
+
+// batchResultLine is a line of the batch output file.
+type batchResultLine struct {
+	ID     string                 `json:"id"`
+	Result *apigen.ChatCompletion `json:"result,omitempty"`
+	Error  json.RawMessage        `json:"error,omitempty"`
+}
+
+// CreateBatch implements providers.BatchModel: builds the JSONL file of chat
+// completion requests, with the system prompt and client defaults applied,
+// and submits it.
+func (c *Client) CreateBatch(ctx context.Context, requests []models.ChatRequest) (models.Batch, error) {
+	if len(requests) == 0 {
+		return models.Batch{}, errors.New("empty batch")
+	}
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for i, request := range requests {
+		if err := validateRequest(request); err != nil {
+			return models.Batch{}, fmt.Errorf("batch request %d: %w", i, err)
+		}
+		if err := encoder.Encode(batchLine{ID: strconv.Itoa(i), Request: c.makeChatRequest(request)}); err != nil {
+			return models.Batch{}, err
+		}
+	}
+
+	method := apigen.ChatCompletions
+	response, err := c.apiClient.PostBatchesWithBodyWithResponse(ctx, &apigen.PostBatchesParams{Method: &method}, "application/octet-stream", &body)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	if response.StatusCode() != http.StatusOK || response.JSON200 == nil || response.JSON200.Id == nil {
+		return models.Batch{}, fmt.Errorf("batch submit failed: status %s, body: %s", response.Status(), response.Body)
+	}
+	batch := models.Batch{ID: *response.JSON200.Id, Status: models.BatchCreated, Total: len(requests)}
+	if response.JSON200.Status != nil {
+		batch.Status = models.BatchStatus(*response.JSON200.Status)
+	}
+	setBatchCounts(&batch, response.JSON200.RequestCounts)
+	return batch, nil
+}
+
+// GetBatch implements providers.BatchModel.
+func (c *Client) GetBatch(ctx context.Context, id string) (models.Batch, error) {
+	params := &apigen.GetBatchesParams{BatchId: &id, ContentType: "application/json"}
+	response, err := c.apiClient.GetBatchesWithResponse(ctx, params)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	if response.StatusCode() != http.StatusOK || response.JSON200 == nil {
+		return models.Batch{}, fmt.Errorf("batch status failed: status %s", response.Status())
+	}
+	if response.JSON200.Batches != nil {
+		for _, item := range *response.JSON200.Batches {
+			if item.Id == nil || *item.Id != id {
+				continue
+			}
+			batch := models.Batch{ID: id}
+			if item.Status != nil {
+				batch.Status = models.BatchStatus(*item.Status)
+			}
+			if item.OutputFileId != nil {
+				batch.OutputFileID = *item.OutputFileId
+			}
+			setBatchCounts(&batch, item.RequestCounts)
+			return batch, nil
+		}
+	}
+	return models.Batch{}, fmt.Errorf("batch %q not found", id)
+}
+
+// BatchResults implements providers.BatchModel: downloads the output file
+// and maps its lines back to request indexes.
+func (c *Client) BatchResults(ctx context.Context, batch models.Batch) ([]models.BatchResult, error) {
+	if batch.OutputFileID == "" {
+		return nil, fmt.Errorf("batch %q has no output file", batch.ID)
+	}
+	response, err := c.apiClient.GetFileIdWithResponse(ctx, batch.OutputFileID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("batch output download failed: status %s", response.Status())
+	}
+	return parseBatchResults(response.Body)
+}
+
+// parseBatchResults decodes the JSONL output file of a batch.
+func parseBatchResults(body []byte) ([]models.BatchResult, error) {
+	var results []models.BatchResult
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var line batchResultLine
+		err := decoder.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("batch output is malformed: %w", err)
+		}
+		index, err := strconv.Atoi(line.ID)
+		if err != nil {
+			return nil, fmt.Errorf("batch output has unknown request id %q", line.ID)
+		}
+		result := models.BatchResult{Index: index}
+		if line.Result != nil {
+			result.Response = mapCompletion(line.Result)
+		} else {
+			result.Error = batchError(line.Error)
+		}
+		results = append(results, result)
+	}
+}
+
+// batchError extracts the message of a failed batch request.
+func batchError(raw json.RawMessage) string {
+	var failure struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &failure); err == nil && failure.Message != "" {
+		return failure.Message
+	}
+	if len(raw) == 0 {
+		return "no result"
+	}
+	return string(raw)
+}
+
+// setBatchCounts copies the request_counts object, whose numbers arrive
+// untyped.
+func setBatchCounts(batch *models.Batch, counts *map[string]interface{}) {
+	if counts == nil {
+		return
+	}
+	for key, target := range map[string]*int{"total": &batch.Total, "completed": &batch.Completed, "failed": &batch.Failed} {
+		if value, ok := (*counts)[key].(float64); ok {
+			*target = int(value)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package gigachat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apigen "pod_api/pkg/apigen/gigachat"
+	"pod_api/pkg/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/batches":
+			require.Equal(t, "chat_completions", r.URL.Query().Get("method"))
+			var ids []string
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				var line batchLine
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+				require.Equal(t, "GigaChat", line.Request.Model)
+				require.Len(t, line.Request.Messages, 2, "system prompt and the user prompt")
+				ids = append(ids, line.ID)
+			}
+			require.Equal(t, []string{"0", "1"}, ids)
+			fmt.Fprint(w, `{"id":"b1","method":"chat_completions","status":"created","request_counts":{"total":2}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/batches":
+			require.Equal(t, "b1", r.URL.Query().Get("batch_id"))
+			fmt.Fprint(w, `{"batches":[{"id":"b1","status":"completed","output_file_id":"out","request_counts":{"total":2,"completed":1,"failed":1}}]}`)
+		case r.URL.Path == "/files/out/content":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			fmt.Fprintln(w, `{"id":"1","error":{"code":400,"message":"bad request"}}`)
+			fmt.Fprintln(w, `{"id":"0","result":{"model":"GigaChat","choices":[{"index":0,"message":{"role":"assistant","content":"{}"}}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	apiClient, err := apigen.NewClientWithResponses(server.URL)
+	require.NoError(t, err)
+	c := &Client{apiClient: apiClient, model: "GigaChat", maxTokens: 1024}
+	ctx := context.Background()
+
+	batch, err := c.CreateBatch(ctx, []models.ChatRequest{models.NewTextRequest("пальто"), models.NewTextRequest("джинсы")})
+	require.NoError(t, err)
+	require.Equal(t, models.Batch{ID: "b1", Status: models.BatchCreated, Total: 2}, batch)
+
+	_, err = c.CreateBatch(ctx, []models.ChatRequest{models.NewTextRequest("")})
+	require.Error(t, err)
+
+	batch, err = c.GetBatch(ctx, "b1")
+	require.NoError(t, err)
+	require.Equal(t, models.Batch{ID: "b1", Status: models.BatchCompleted, Total: 2, Completed: 1, Failed: 1, OutputFileID: "out"}, batch)
+
+	results, err := c.BatchResults(ctx, batch)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, 1, results[0].Index)
+	require.Equal(t, "bad request", results[0].Error)
+	require.Equal(t, 0, results[1].Index)
+	require.Equal(t, "{}", results[1].Response.Choices[0].Message.Content)
+}
@@ -16,10 +16,14 @@ import (
 	"pod_api/pkg/config"
 	"pod_api/pkg/models"
 	"pod_api/pkg/prompting"
+	"pod_api/pkg/providers"
 
 	"github.com/google/uuid"
 )
 
+// ProviderName identifies GigaChat in configuration.
+const ProviderName = "gigachat"
+
 type Client struct {
 	baseURL string
 
@@ -31,10 +35,12 @@ type Client struct {
 	model     string
 	maxTokens int32
 
-	// Generated API clients
-	apiClient   *apigen.ClientWithResponses
-	tokenClient *apigen.ClientWithResponses
-	httpClient  *http.Client
+	// Generated API clients; streamClient has no overall timeout so that
+	// long SSE answers are bounded by the request context only.
+	apiClient    *apigen.ClientWithResponses
+	streamClient *apigen.ClientWithResponses
+	tokenClient  *apigen.ClientWithResponses
+	httpClient   *http.Client
 
 	// Bearer token management
 	tokenMu       sync.RWMutex
@@ -42,6 +48,10 @@ type Client struct {
 	tokenExpiry   time.Time
 	refreshLeeway time.Duration
 	stopCh        chan struct{}
+
+	// Uploaded files cleanup (see fileSweeper)
+	fileSweepInterval time.Duration
+	fileMaxAge        time.Duration
 }
 
 // NewClient constructs a GigaChat client.
@@ -59,6 +69,15 @@ type Options struct {
 	Model         string
 	RefreshLeeway time.Duration
 	MaxTokens     int32
+
+	// FileSweepInterval is how often leaked uploads are removed (0 disables).
+	FileSweepInterval time.Duration
+	// FileMaxAge is the age after which an upload is considered leaked.
+	FileMaxAge time.Duration
+
+	// HTTPClient sends API and token requests (nil uses a client without
+	// timeout). Streams reuse its transport without the overall timeout.
+	HTTPClient *http.Client
 }
 
 // NewOptions returns sensible defaults.
@@ -68,6 +87,9 @@ func NewOptions() Options {
 		Model:         "GigaChat-2",
 		RefreshLeeway: 10 * time.Second,
 		MaxTokens:     1024,
+
+		FileSweepInterval: 5 * time.Minute,
+		FileMaxAge:        15 * time.Minute,
 	}
 }
 
@@ -83,6 +105,10 @@ func NewClientWithOptions(url string, key string, opts Options) (*Client, error)
 	if opts.MaxTokens <= 0 {
 		opts.MaxTokens = 1024
 	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
 
 	c := &Client{
 		baseURL:       url,
@@ -91,18 +117,35 @@ func NewClientWithOptions(url string, key string, opts Options) (*Client, error)
 		model:         opts.Model,
 		refreshLeeway: opts.RefreshLeeway,
 		stopCh:        make(chan struct{}),
+		httpClient:    httpClient,
 		maxTokens:     opts.MaxTokens,
+
+		fileSweepInterval: opts.FileSweepInterval,
+		fileMaxAge:        opts.FileMaxAge,
 	}
 
 	// API client for chat and other methods; attach bearer editor
-	apiClient, err := apigen.NewClientWithResponses(url, apigen.WithRequestEditorFn(c.bearerAuthEditor))
+	apiClient, err := apigen.NewClientWithResponses(url,
+		apigen.WithHTTPClient(httpClient),
+		apigen.WithRequestEditorFn(c.bearerAuthEditor),
+	)
 	if err != nil {
 		return nil, err
 	}
 	c.apiClient = apiClient
 
+	// Stream client: same transport, no overall timeout
+	streamClient, err := apigen.NewClientWithResponses(url,
+		apigen.WithHTTPClient(&http.Client{Transport: httpClient.Transport}),
+		apigen.WithRequestEditorFn(c.bearerAuthEditor),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.streamClient = streamClient
+
 	// Token client (no default editors; we pass Basic per request)
-	tokenClient, err := apigen.NewClientWithResponses(url)
+	tokenClient, err := apigen.NewClientWithResponses(url, apigen.WithHTTPClient(httpClient))
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +158,7 @@ func NewClientWithOptions(url string, key string, opts Options) (*Client, error)
 
 	// Start background refresh
 	go c.tokenRefresher()
+	c.startFileSweeper()
 
 	return c, nil
 }
@@ -135,6 +179,10 @@ func NewFromConfig(cfg config.Config) (*Client, error) {
 	if cfg.Gigachat.MaxTokens > 0 {
 		opts.MaxTokens = int32(cfg.Gigachat.MaxTokens)
 	}
+	opts.FileSweepInterval = cfg.Gigachat.FileSweepInterval
+	if cfg.Gigachat.FileMaxAge > 0 {
+		opts.FileMaxAge = cfg.Gigachat.FileMaxAge
+	}
 	if cfg.Gigachat.BasicKey == "" {
 		return nil, errors.New("GIGACHAT_BASIC_KEY is empty")
 	}
@@ -153,6 +201,9 @@ func NewFromConfig(cfg config.Config) (*Client, error) {
 		stopCh:        make(chan struct{}),
 		httpClient:    httpClient,
 		maxTokens:     opts.MaxTokens,
+
+		fileSweepInterval: opts.FileSweepInterval,
+		fileMaxAge:        opts.FileMaxAge,
 	}
 
 	// API + token clients using the custom HTTP client
@@ -164,17 +215,27 @@ func NewFromConfig(cfg config.Config) (*Client, error) {
 		return nil, err
 	}
 
+	streamClient, err := apigen.NewClientWithResponses(cfg.Gigachat.URL,
+		apigen.WithHTTPClient(&http.Client{Transport: httpClient.Transport}),
+		apigen.WithRequestEditorFn(c.bearerAuthEditor),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	tokenClient, err := apigen.NewClientWithResponses(cfg.Gigachat.AuthURL, apigen.WithHTTPClient(httpClient))
 	if err != nil {
 		return nil, err
 	}
 	c.apiClient = apiClient
+	c.streamClient = streamClient
 	c.tokenClient = tokenClient
 
 	if err := c.refreshToken(context.Background()); err != nil {
 		return nil, err
 	}
 	go c.tokenRefresher()
+	c.startFileSweeper()
 	return c, nil
 }
 
@@ -297,7 +358,7 @@ func (c *Client) tokenRefresher() {
 	}
 }
 
-// Close stops background token refresh.
+// Close stops background token refresh and file sweeping.
 func (c *Client) Close() {
 	select {
 	case <-c.stopCh:
@@ -307,54 +368,122 @@ func (c *Client) Close() {
 	}
 }
 
-func makePromt(userMessage string) []apigen.Message {
+// makePromt prepends the system prompt to the conversation history.
+func makePromt(history []models.ChatMessage) []apigen.Message {
 	sysRole := apigen.MessageRoleSystem
 	sysContent := prompting.SystemPrompt()
 
-	userRole := apigen.MessageRoleUser
-	userContent := userMessage
+	messages := make([]apigen.Message, 0, len(history)+1)
+	messages = append(messages, apigen.Message{Role: &sysRole, Content: &sysContent})
+	for i := range history {
+		role := apigen.MessageRole(history[i].Role)
+		content := history[i].Content
+		messages = append(messages, apigen.Message{Role: &role, Content: &content})
+	}
+	return messages
+}
 
-	return []apigen.Message{
-		{Role: &sysRole, Content: &sysContent},
-		{Role: &userRole, Content: &userContent},
-		// {Role: &assistantRole, Content: &assistantContent},
-		// {Role: &functionRole, Content: &functionContent},
+// makeChatRequest builds a chat completion request with client defaults.
+func (c *Client) makeChatRequest(request models.ChatRequest) apigen.Chat {
+	options := request.Options
+	maxTokens := c.maxTokens
+	if options.MaxTokens != nil {
+		maxTokens = int32(*options.MaxTokens)
+	}
+	chat := apigen.Chat{
+		Model:     c.model,
+		Messages:  makePromt(request.Messages),
+		MaxTokens: &maxTokens,
 	}
+	if options.Model != "" {
+		chat.Model = options.Model
+	}
+	chat.Temperature = toFloat32(options.Temperature)
+	chat.TopP = toFloat32(options.TopP)
+	chat.RepetitionPenalty = toFloat32(options.RepetitionPenalty)
+	return chat
 }
 
-// SendMessage implements api.TextModel: sends a message to chat completions.
-func (c *Client) SendMessage(userMessage string) (*models.ChatResponse, error) {
-	if userMessage == "" {
-		return nil, errors.New("empty message")
+func toFloat32(v *float64) *float32 {
+	if v == nil {
+		return nil
 	}
+	f := float32(*v)
+	return &f
+}
 
-	messages := makePromt(userMessage)
+// makeChatParams forwards the session id so GigaChat can reuse cached prompt prefix.
+func makeChatParams(request models.ChatRequest) *apigen.PostChatParams {
+	if request.SessionID == "" {
+		return nil
+	}
+	sessionID := request.SessionID
+	return &apigen.PostChatParams{XSessionID: &sessionID}
+}
 
-	maxTokens := c.maxTokens
-	request := apigen.Chat{
-		Model:     c.model,
-		Messages:  messages,
-		MaxTokens: &maxTokens,
+// validateRequest checks that the conversation ends with a non-empty user turn.
+func validateRequest(request models.ChatRequest) error {
+	if len(request.Messages) == 0 {
+		return errors.New("empty message")
 	}
+	last := request.Messages[len(request.Messages)-1]
+	if last.Role != models.RoleUser || last.Content == "" {
+		return errors.New("empty message")
+	}
+	return nil
+}
+
+// SupportsModel implements providers.ModelSelector.
+func (c *Client) SupportsModel(model string) bool {
+	return config.IsGigachatModel(model)
+}
+
+// Name implements providers.Provider.
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+// Capabilities implements providers.Provider.
+func (c *Client) Capabilities() providers.Capabilities {
+	return providers.Capabilities{
+		providers.CapabilityText,
+		providers.CapabilityVision,
+		providers.CapabilityStreaming,
+		providers.CapabilityBatch,
+	}
+}
+
+// SendMessage implements providers.TextModel: sends a conversation to chat completions.
+func (c *Client) SendMessage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	return c.postChat(ctx, request, c.makeChatRequest(request))
+}
 
+// postChat executes a non-streaming chat request and maps the answer.
+func (c *Client) postChat(ctx context.Context, request models.ChatRequest, chat apigen.Chat) (*models.ChatResponse, error) {
 	// Execute request with bearer editor (already attached globally).
-	response, err := c.apiClient.PostChatWithResponse(context.Background(), nil, request)
+	response, err := c.apiClient.PostChatWithResponse(ctx, makeChatParams(request), chat)
 	if err != nil {
 		return nil, err
 	}
 
-	// Accept 200 (JSON or stream) as success. Any error JSON codes -> error.
-	if response.JSON200 == nil && (response.StatusCode() != 200) {
+	// Accept only 200 with JSON body as success. Any error JSON codes -> error.
+	if response.StatusCode() != http.StatusOK {
 		return nil, errors.New("chat request failed: status " + response.Status())
 	}
-
-	// Map GigaChat response to unified ChatResponse
 	if response.JSON200 == nil {
-		// No JSON body (maybe streaming or empty)
-		return &models.ChatResponse{}, nil
+		// Streaming or empty body is never expected here
+		return nil, fmt.Errorf("chat request returned non-JSON body: %q", response.HTTPResponse.Header.Get("Content-Type"))
 	}
 
-	gc := response.JSON200
+	return mapCompletion(response.JSON200), nil
+}
+
+// mapCompletion converts a GigaChat completion to the unified model.
+func mapCompletion(gc *apigen.ChatCompletion) *models.ChatResponse {
 	out := &models.ChatResponse{}
 	if gc.Created != nil {
 		out.Created = int64(*gc.Created)
@@ -365,24 +494,7 @@ func (c *Client) SendMessage(userMessage string) (*models.ChatResponse, error) {
 	if gc.Object != nil {
 		out.Object = *gc.Object
 	}
-
-	// Map usage
-	if gc.Usage != nil {
-		u := &models.ChatUsage{}
-		if gc.Usage.PromptTokens != nil {
-			u.PromptTokens = *gc.Usage.PromptTokens
-		}
-		if gc.Usage.CompletionTokens != nil {
-			u.CompletionTokens = *gc.Usage.CompletionTokens
-		}
-		if gc.Usage.TotalTokens != nil {
-			u.TotalTokens = *gc.Usage.TotalTokens
-		}
-		if gc.Usage.PrecachedPromptTokens != nil {
-			u.PrecachedPromptTokens = *gc.Usage.PrecachedPromptTokens
-		}
-		out.Usage = u
-	}
+	out.Usage = mapUsage(gc.Usage)
 
 	// Map choices/messages
 	if gc.Choices != nil {
@@ -427,5 +539,26 @@ func (c *Client) SendMessage(userMessage string) (*models.ChatResponse, error) {
 		}
 	}
 
-	return out, nil
+	return out
+}
+
+// mapUsage converts GigaChat token accounting to the unified model.
+func mapUsage(usage *apigen.Usage) *models.ChatUsage {
+	if usage == nil {
+		return nil
+	}
+	u := &models.ChatUsage{}
+	if usage.PromptTokens != nil {
+		u.PromptTokens = *usage.PromptTokens
+	}
+	if usage.CompletionTokens != nil {
+		u.CompletionTokens = *usage.CompletionTokens
+	}
+	if usage.TotalTokens != nil {
+		u.TotalTokens = *usage.TotalTokens
+	}
+	if usage.PrecachedPromptTokens != nil {
+		u.PrecachedPromptTokens = *usage.PrecachedPromptTokens
+	}
+	return u
 }
@@ -0,0 +1,215 @@
+package gigachat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	apigen "pod_api/pkg/apigen/gigachat"
+	"pod_api/pkg/models"
+)
+
+// fileDeleteTimeout bounds cleanup of uploaded files, which outlives the request context.
+const fileDeleteTimeout = 10 * time.Second
+
+// filePrefix marks uploads made by this service, so the sweeper never
+// touches other files of the account.
+const filePrefix = "pod_api-"
+
+// imageExtensions maps supported image types to upload file extensions.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/jpg":  ".jpg",
+	"image/png":  ".png",
+}
+
+// SupportsImageType implements providers.ImageTypeSelector.
+func (c *Client) SupportsImageType(contentType string) bool {
+	_, ok := imageExtensions[contentType]
+	return ok
+}
+
+// uploadFile stores image bytes in GigaChat storage and returns the file id.
+func (c *Client) uploadFile(ctx context.Context, image models.Image) (string, error) {
+	if len(image.Data) == 0 {
+		return "", errors.New("image data is required for upload")
+	}
+	ext, ok := imageExtensions[image.ContentType]
+	if !ok {
+		return "", fmt.Errorf("unsupported image type for gigachat: %q", image.ContentType)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s%s%s"`, filePrefix, uuid.NewString(), ext))
+	header.Set("Content-Type", image.ContentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(image.Data); err != nil {
+		return "", err
+	}
+	if err := writer.WriteField("purpose", string(apigen.FileUploadPurposeGeneral)); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	response, err := c.apiClient.PostFileWithBodyWithResponse(ctx, writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode() != http.StatusOK || response.JSON200 == nil || response.JSON200.Id == nil {
+		return "", fmt.Errorf("file upload failed: status %s, body: %s", response.Status(), response.Body)
+	}
+	return *response.JSON200.Id, nil
+}
+
+// deleteFile removes a file from GigaChat storage.
+func (c *Client) deleteFile(ctx context.Context, id string) error {
+	response, err := c.apiClient.FileDeleteWithResponse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode() != http.StatusOK {
+		return fmt.Errorf("file delete failed: status %s", response.Status())
+	}
+	return nil
+}
+
+// SendImage implements providers.ImageModel: uploads message images to
+// GigaChat storage, references them as attachments and deletes the files
+// once the answer is received.
+func (c *Client) SendImage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	request.Messages = splitImages(request.Messages)
+	chat := c.makeChatRequest(request)
+
+	var fileIDs []string
+	defer func() {
+		// Files must be removed even when the request was cancelled.
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fileDeleteTimeout)
+		defer cancel()
+		for _, id := range fileIDs {
+			if err := c.deleteFile(cleanupCtx, id); err != nil {
+				log.Warn().Err(err).Str("file_id", id).Msg("gigachat file not deleted")
+			}
+		}
+	}()
+
+	for i, message := range request.Messages {
+		if len(message.Images) == 0 {
+			continue
+		}
+		id, err := c.uploadFile(ctx, message.Images[0])
+		if err != nil {
+			return nil, err
+		}
+		fileIDs = append(fileIDs, id)
+		attachments := []string{id}
+		// Messages are shifted by the system prompt
+		chat.Messages[i+1].Attachments = &attachments
+	}
+
+	return c.postChat(ctx, request, chat)
+}
+
+// splitImages spreads the images of a message over consecutive user
+// messages, since GigaChat accepts a single attachment per message. The
+// original text goes with the last image.
+func splitImages(messages []models.ChatMessage) []models.ChatMessage {
+	out := make([]models.ChatMessage, 0, len(messages))
+	for _, message := range messages {
+		count := len(message.Images)
+		for i := 0; i < count-1; i++ {
+			out = append(out, models.ChatMessage{
+				Role:    message.Role,
+				Content: fmt.Sprintf("Фото %d из %d", i+1, count),
+				Images:  message.Images[i : i+1],
+			})
+		}
+		if count > 1 {
+			message.Images = message.Images[count-1:]
+		}
+		out = append(out, message)
+	}
+	return out
+}
+
+// startFileSweeper runs fileSweeper in background unless it is disabled.
+func (c *Client) startFileSweeper() {
+	if c.fileSweepInterval > 0 {
+		go c.fileSweeper()
+	}
+}
+
+// fileSweeper periodically removes uploads that outlived their chat request,
+// e.g. when the delete call failed or the process was restarted mid-request.
+func (c *Client) fileSweeper() {
+	ticker := time.NewTicker(c.fileSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.fileSweepInterval)
+			deleted, err := c.sweepFiles(ctx, time.Now())
+			cancel()
+			if err != nil {
+				log.Warn().Err(err).Msg("gigachat file sweep failed")
+			} else if deleted > 0 {
+				log.Info().Int("deleted", deleted).Msg("gigachat leaked files removed")
+			}
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// sweepFiles deletes own uploads older than fileMaxAge and returns their count.
+func (c *Client) sweepFiles(ctx context.Context, now time.Time) (int, error) {
+	response, err := c.apiClient.GetFilesWithResponse(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if response.StatusCode() != http.StatusOK || response.JSON200 == nil {
+		return 0, fmt.Errorf("file list failed: status %s", response.Status())
+	}
+	if response.JSON200.Data == nil {
+		return 0, nil
+	}
+
+	deleted := 0
+	for _, file := range *response.JSON200.Data {
+		if file.Id == nil || file.Filename == nil || file.CreatedAt == nil {
+			continue
+		}
+		if !strings.HasPrefix(*file.Filename, filePrefix) {
+			continue
+		}
+		if now.Sub(time.Unix(int64(*file.CreatedAt), 0)) < c.fileMaxAge {
+			// May still be attached to an in-flight request
+			continue
+		}
+		if err := c.deleteFile(ctx, *file.Id); err != nil {
+			log.Warn().Err(err).Str("file_id", *file.Id).Msg("gigachat file not deleted")
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
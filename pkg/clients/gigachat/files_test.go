@@ -0,0 +1,62 @@
+package gigachat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apigen "pod_api/pkg/apigen/gigachat"
+	"pod_api/pkg/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweepFiles(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	old := now.Add(-time.Hour).Unix()
+	fresh := now.Add(-time.Minute).Unix()
+
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"data":[
+				{"id":"leaked","filename":"%sa.jpg","created_at":%d,"object":"file","purpose":"general","bytes":1},
+				{"id":"in-flight","filename":"%sb.jpg","created_at":%d,"object":"file","purpose":"general","bytes":1},
+				{"id":"foreign","filename":"photo.jpg","created_at":%d,"object":"file","purpose":"general","bytes":1}
+			]}`, filePrefix, old, filePrefix, fresh, old)
+		case "/files/leaked/delete":
+			deleted = append(deleted, "leaked")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"leaked","deleted":true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	apiClient, err := apigen.NewClientWithResponses(server.URL)
+	require.NoError(t, err)
+	c := &Client{apiClient: apiClient, fileMaxAge: 15 * time.Minute}
+
+	count, err := c.sweepFiles(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, []string{"leaked"}, deleted)
+}
+
+func TestSplitImages(t *testing.T) {
+	images := []models.Image{{URL: "front"}, {URL: "back"}, {URL: "label"}}
+	messages := splitImages([]models.ChatMessage{{Role: models.RoleUser, Content: "что это?", Images: images}})
+
+	require.Len(t, messages, 3)
+	require.Equal(t, "Фото 1 из 3", messages[0].Content)
+	require.Equal(t, images[:1], messages[0].Images)
+	require.Equal(t, images[1:2], messages[1].Images)
+	require.Equal(t, "что это?", messages[2].Content)
+	require.Equal(t, images[2:], messages[2].Images)
+}
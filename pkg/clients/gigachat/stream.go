@@ -0,0 +1,127 @@
+package gigachat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	apigen "pod_api/pkg/apigen/gigachat"
+	"pod_api/pkg/models"
+)
+
+// maxStreamLineSize bounds a single SSE line coming from GigaChat.
+const maxStreamLineSize = 1 << 20
+
+// streamChunk mirrors a single `data:` payload of the GigaChat SSE stream.
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+			Role    string `json:"role"`
+		} `json:"delta"`
+		Index        int32  `json:"index"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Object  string        `json:"object"`
+	Usage   *apigen.Usage `json:"usage"`
+}
+
+// stream implements models.ChatStream over a GigaChat SSE response body.
+type stream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+func newStream(body io.ReadCloser) *stream {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+	return &stream{body: body, scanner: scanner}
+}
+
+// Next returns the next chunk; io.EOF is returned after `data: [DONE]`
+// or when the upstream closes the connection.
+func (s *stream) Next() (models.ChatChunk, error) {
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			// Skip blank separators, comments and event names
+			continue
+		}
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if payload == "" {
+			continue
+		}
+		if payload == "[DONE]" {
+			return models.ChatChunk{}, io.EOF
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			return models.ChatChunk{}, fmt.Errorf("decode stream chunk: %w", err)
+		}
+
+		out := models.ChatChunk{
+			Model: chunk.Model,
+			Usage: mapUsage(chunk.Usage),
+		}
+		for _, ch := range chunk.Choices {
+			out.Content += ch.Delta.Content
+			if ch.FinishReason != "" {
+				out.FinishReason = ch.FinishReason
+			}
+		}
+		return out, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return models.ChatChunk{}, err
+	}
+	return models.ChatChunk{}, io.EOF
+}
+
+// Close releases the upstream connection.
+func (s *stream) Close() error {
+	return s.body.Close()
+}
+
+// StreamMessage implements api.StreamingTextModel: sends a conversation with
+// `stream: true` and returns a stream of completion deltas.
+// The stream is bound to ctx: cancelling it aborts the upstream request.
+// It is sent through streamClient, so the HTTP client timeout does not cut
+// answers that take longer than a regular completion.
+func (c *Client) StreamMessage(ctx context.Context, request models.ChatRequest) (models.ChatStream, error) {
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	chat := c.makeChatRequest(request)
+	streamFlag := true
+	chat.Stream = &streamFlag
+
+	acceptStream := func(_ context.Context, req *http.Request) error {
+		req.Header.Set("Accept", "text/event-stream")
+		return nil
+	}
+
+	response, err := c.streamClient.PostChat(ctx, makeChatParams(request), chat, acceptStream)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("chat stream request failed: status %s, body: %s", response.Status, body)
+	}
+	if ctype := response.Header.Get("Content-Type"); !strings.Contains(ctype, "text/event-stream") {
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("chat stream request returned unexpected content type: %q", ctype)
+	}
+
+	return newStream(response.Body), nil
+}
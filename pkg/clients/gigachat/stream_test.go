@@ -0,0 +1,95 @@
+package gigachat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pod_api/pkg/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamNext(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"choices":[{"delta":{"content":"При","role":"assistant"},"index":0}],"created":1,"model":"GigaChat-2","object":"chat.completion"}`,
+		``,
+		`data: {"choices":[{"delta":{"content":"вет"},"index":0,"finish_reason":"stop"}],"created":1,"model":"GigaChat-2","object":"chat.completion"}`,
+		``,
+		`data: {"choices":[{"delta":{"content":""},"index":0}],"model":"GigaChat-2","usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	s := newStream(io.NopCloser(strings.NewReader(body)))
+
+	chunk, err := s.Next()
+	require.NoError(t, err)
+	require.Equal(t, "При", chunk.Content)
+	require.Equal(t, "GigaChat-2", chunk.Model)
+
+	chunk, err = s.Next()
+	require.NoError(t, err)
+	require.Equal(t, "вет", chunk.Content)
+	require.Equal(t, "stop", chunk.FinishReason)
+
+	chunk, err = s.Next()
+	require.NoError(t, err)
+	require.NotNil(t, chunk.Usage)
+	require.EqualValues(t, 5, chunk.Usage.TotalTokens)
+
+	_, err = s.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestStreamNextInvalidPayload(t *testing.T) {
+	s := newStream(io.NopCloser(strings.NewReader("data: {broken\n\n")))
+
+	_, err := s.Next()
+	require.Error(t, err)
+}
+
+func TestStreamMessageOutlivesClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"token","expires_at":%d}`, time.Now().Add(time.Hour).UnixMilli())
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"При", "вет"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q},\"index\":0}]}\n\n", content)
+			w.(http.Flusher).Flush()
+			time.Sleep(100 * time.Millisecond)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	opts := NewOptions()
+	opts.FileSweepInterval = 0
+	opts.HTTPClient = &http.Client{Timeout: 50 * time.Millisecond}
+	c, err := NewClientWithOptions(server.URL, "key", opts)
+	require.NoError(t, err)
+	defer c.Close()
+
+	s, err := c.StreamMessage(context.Background(), models.NewTextRequest("привет"))
+	require.NoError(t, err)
+	defer s.Close()
+	var content string
+	for {
+		chunk, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content += chunk.Content
+	}
+	require.Equal(t, "Привет", content)
+}
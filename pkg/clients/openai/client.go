@@ -3,32 +3,30 @@ package openai
 import (
 	"context"
 	"fmt"
-	"strings"
+	"slices"
 	"time"
 
+	"pod_api/pkg/fashion"
 	"pod_api/pkg/models"
 	"pod_api/pkg/prompting"
+	"pod_api/pkg/providers"
 
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 )
 
-type Client struct {
-	client openai.Client
-	model  string
-}
-
-func isModelInList(model string, models []openai.Model) bool {
-	for i := range models {
-		if models[i].ID == model {
-			return true
-		}
-	}
+// ProviderName identifies OpenAI-compatible backends in configuration.
+const ProviderName = "openai"
 
-	return false
+type Client struct {
+	client    openai.Client
+	model     string
+	maxTokens int
+	// models lists model ids served by the endpoint.
+	models []string
 }
 
-func NewClient(key string, url string, model string, requestTimeout time.Duration) (*Client, error) {
+func NewClient(key string, url string, model string, requestTimeout time.Duration, maxTokens int) (*Client, error) {
 	client := openai.NewClient(option.WithAPIKey(key),
 		option.WithBaseURL(url),
 		option.WithRequestTimeout(requestTimeout))
@@ -39,58 +37,143 @@ func NewClient(key string, url string, model string, requestTimeout time.Duratio
 		return nil, fmt.Errorf("connection test failed: %w", err)
 	}
 
-	if !isModelInList(model, modelList.Data) {
+	available := make([]string, 0, len(modelList.Data))
+	for i := range modelList.Data {
+		available = append(available, modelList.Data[i].ID)
+	}
+	if !slices.Contains(available, model) {
 		return nil, fmt.Errorf("such model does not exists: %s", model)
 	}
 
 	return &Client{
-		client: client,
-		model:  model,
+		client:    client,
+		model:     model,
+		maxTokens: maxTokens,
+		models:    available,
 	}, nil
 }
 
-func (c *Client) makePromtParams(message string, imageURL string) openai.ChatCompletionNewParams {
+func (c *Client) makePromtParams(request models.ChatRequest) openai.ChatCompletionNewParams {
+	messages := []openai.ChatCompletionMessageParamUnion{
+		{
+			OfSystem: &openai.ChatCompletionSystemMessageParam{
+				Content: openai.ChatCompletionSystemMessageParamContentUnion{
+					OfString: openai.String(prompting.SystemPrompt()),
+				},
+			},
+		},
+	}
+	for _, message := range request.Messages {
+		messages = append(messages, makeMessage(message))
+	}
+
+	options := request.Options
+	model := c.model
+	if options.Model != "" {
+		model = options.Model
+	}
+	maxTokens := c.maxTokens
+	if options.MaxTokens != nil {
+		maxTokens = *options.MaxTokens
+	}
+
 	// var jsonFmt constant.JSONObject
-	return openai.ChatCompletionNewParams{
-		Model:     openai.ChatModel(c.model),
-		MaxTokens: openai.Int(50000),
+	params := openai.ChatCompletionNewParams{
+		Model:     openai.ChatModel(model),
+		MaxTokens: openai.Int(int64(maxTokens)),
 		// ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
 		// 	OfJSONObject: &shared.ResponseFormatJSONObjectParam{
 		// 		Type: jsonFmt.Default(),
 		// 	},
 		// },
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			{
-				OfSystem: &openai.ChatCompletionSystemMessageParam{
-					Content: openai.ChatCompletionSystemMessageParamContentUnion{
-						OfString: openai.String(prompting.SystemPrompt()),
-					},
+		Messages: messages,
+	}
+	if options.Temperature != nil {
+		params.Temperature = openai.Float(*options.Temperature)
+	}
+	if options.TopP != nil {
+		params.TopP = openai.Float(*options.TopP)
+	}
+	if options.RepetitionPenalty != nil {
+		// Not part of the OpenAI API; OpenAI-compatible gateways (vLLM,
+		// OpenRouter) accept it as an extra field.
+		params.SetExtraFields(map[string]any{"repetition_penalty": *options.RepetitionPenalty})
+	}
+	return params
+}
+
+// makeMessage converts a history message; user images become content parts.
+func makeMessage(message models.ChatMessage) openai.ChatCompletionMessageParamUnion {
+	if message.Role == models.RoleAssistant {
+		return openai.AssistantMessage(message.Content)
+	}
+
+	parts := []openai.ChatCompletionContentPartUnionParam{
+		{OfText: &openai.ChatCompletionContentPartTextParam{
+			Text: message.Content,
+		}},
+	}
+	for _, image := range message.Images {
+		url := image.URL
+		if url == "" {
+			// Inline delivery: the endpoint cannot reach our image links.
+			url = image.DataURL()
+		}
+		parts = append(parts, openai.ChatCompletionContentPartUnionParam{
+			OfImageURL: &openai.ChatCompletionContentPartImageParam{
+				ImageURL: openai.ChatCompletionContentPartImageImageURLParam{
+					URL:    url,
+					Detail: "auto",
 				},
 			},
-			{
-				OfUser: &openai.ChatCompletionUserMessageParam{
-					Content: openai.ChatCompletionUserMessageParamContentUnion{
-						OfArrayOfContentParts: []openai.ChatCompletionContentPartUnionParam{
-							{OfText: &openai.ChatCompletionContentPartTextParam{
-								Text: message,
-							}},
-							{OfImageURL: &openai.ChatCompletionContentPartImageParam{
-								ImageURL: openai.ChatCompletionContentPartImageImageURLParam{
-									URL:    imageURL,
-									Detail: "auto",
-								},
-							}},
-						},
-					},
-				},
+		})
+	}
+	return openai.ChatCompletionMessageParamUnion{
+		OfUser: &openai.ChatCompletionUserMessageParam{
+			Content: openai.ChatCompletionUserMessageParamContentUnion{
+				OfArrayOfContentParts: parts,
 			},
 		},
 	}
 }
 
-func (c *Client) SendImage(message string, imageURL string) (*models.ChatResponse, error) {
-	params := c.makePromtParams(message, imageURL)
-	response, err := c.client.Chat.Completions.New(context.Background(), params)
+// SupportsModel implements providers.ModelSelector.
+func (c *Client) SupportsModel(model string) bool {
+	return slices.Contains(c.models, model)
+}
+
+// imageTypes lists image formats accepted by the Chat Completions API.
+var imageTypes = []string{"image/png", "image/jpeg", "image/webp", "image/gif"}
+
+// SupportsImageType implements providers.ImageTypeSelector.
+func (c *Client) SupportsImageType(contentType string) bool {
+	return slices.Contains(imageTypes, contentType)
+}
+
+// Name implements providers.Provider.
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+// Capabilities implements providers.Provider.
+func (c *Client) Capabilities() providers.Capabilities {
+	return providers.Capabilities{providers.CapabilityText, providers.CapabilityVision}
+}
+
+// SendMessage implements providers.TextModel.
+func (c *Client) SendMessage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	return c.complete(ctx, request)
+}
+
+// SendImage implements providers.ImageModel: sends a conversation whose user
+// messages may carry image URLs to the vision model.
+func (c *Client) SendImage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	return c.complete(ctx, request)
+}
+
+func (c *Client) complete(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	params := c.makePromtParams(request)
+	response, err := c.client.Chat.Completions.New(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("openai request failed: %w", err)
 	}
@@ -109,7 +192,7 @@ func (c *Client) SendImage(message string, imageURL string) (*models.ChatRespons
 
 	for _, choice := range response.Choices {
 		message := models.ChatMessage{
-			Content: trimMessage(choice.Message.Content),
+			Content: fashion.StripFences(choice.Message.Content),
 			Role:    string(choice.Message.Role),
 		}
 		out.Choices = append(out.Choices, models.ChatChoice{
@@ -121,7 +204,3 @@ func (c *Client) SendImage(message string, imageURL string) (*models.ChatRespons
 
 	return out, nil
 }
-
-func trimMessage(message string) string {
-	return strings.TrimPrefix(strings.TrimSuffix(message, "\n```"), "```json\n")
-}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/caarlos0/env/v9"
@@ -19,14 +22,46 @@ type Config struct {
 		BaseURL string `env:"BASE_URL" envDefault:""`
 	}
 
+	Providers struct {
+		// Text lists providers serving text chat in fallback order: gigachat, openai, fake.
+		Text []string `env:"TEXT_PROVIDER" envDefault:"gigachat" envSeparator:","`
+
+		// Vision lists providers serving image chat in fallback order: gigachat, openai, fake.
+		Vision []string `env:"VISION_PROVIDER" envDefault:"openai" envSeparator:","`
+	}
+
+	// Breaker controls per-provider circuit breakers.
+	Breaker struct {
+		// FailureThreshold opens the breaker after this many consecutive failures (0 disables).
+		FailureThreshold int `env:"BREAKER_FAILURE_THRESHOLD" envDefault:"5"`
+
+		// ErrorRate opens the breaker when the share of failures in the window reaches it (0 disables).
+		ErrorRate float64 `env:"BREAKER_ERROR_RATE" envDefault:"0.5"`
+
+		// Window is the number of recent calls used for the error rate.
+		Window int `env:"BREAKER_WINDOW" envDefault:"20"`
+
+		// MinCalls is the minimal number of calls in the window before the error rate applies.
+		MinCalls int `env:"BREAKER_MIN_CALLS" envDefault:"10"`
+
+		// OpenTimeout is how long a tripped provider is skipped before probing.
+		OpenTimeout time.Duration `env:"BREAKER_OPEN_TIMEOUT" envDefault:"30s"`
+
+		// HalfOpenProbes limits concurrent probe calls to a recovering provider.
+		HalfOpenProbes int `env:"BREAKER_HALF_OPEN_PROBES" envDefault:"1"`
+	}
+
 	OpenAI struct {
 		URL string `env:"OPENAI_URL" envDefault:"https://api.aitunnel.ru/v1"`
 
-		BasicKey string `env:"OPENAI_BASIC_KEY,required"`
+		BasicKey string `env:"OPENAI_BASIC_KEY"`
 
-		Model string `env:"OPENAI_MODEL,required"`
+		Model string `env:"OPENAI_MODEL"`
 
 		RequestTimeout time.Duration `env:"OPENAI_REQUEST_TIMEOUT" envDefault:"30s"`
+
+		// Max tokens to request in chat completions unless the client asks for less
+		MaxTokens int `env:"OPENAI_MAX_TOKENS" envDefault:"50000"`
 	}
 
 	Gigachat struct {
@@ -47,21 +82,225 @@ type Config struct {
 		TokenRefreshLeewaySeconds int `env:"GIGACHAT_TOKEN_REFRESH_LEEWAY_SECONDS" envDefault:"10"`
 
 		// Basic auth token (base64 of client_id:client_secret) used to obtain OAuth access token
-		BasicKey string `env:"GIGACHAT_BASIC_KEY,required"`
+		BasicKey string `env:"GIGACHAT_BASIC_KEY"`
 
 		// URL to a PEM-encoded Root CA certificate to trust for GigaChat API TLS
 		RootCAURL string `env:"GIGACHAT_ROOT_CA_URL" envDefault:"https://gu-st.ru/content/lending/russian_trusted_root_ca_pem.crt"`
 
 		// Max tokens to request in chat completions
 		MaxTokens int `env:"GIGACHAT_MAX_TOKENS" envDefault:"1024"`
+
+		// How often uploaded image files left in GigaChat storage are removed (0 disables)
+		FileSweepInterval time.Duration `env:"GIGACHAT_FILE_SWEEP_INTERVAL" envDefault:"5m"`
+
+		// Uploaded files older than this are considered leaked by the sweeper
+		FileMaxAge time.Duration `env:"GIGACHAT_FILE_MAX_AGE" envDefault:"15m"`
+	}
+
+	// Generation limits per-request generation options of the public API.
+	Generation struct {
+		// AllowedParams lists options clients may set:
+		// model, temperature, top_p, max_tokens, repetition_penalty.
+		AllowedParams []string `env:"GENERATION_ALLOWED_PARAMS" envDefault:"temperature,top_p,max_tokens,repetition_penalty" envSeparator:","`
+
+		// Models maps models clients may select to their max_tokens limit,
+		// e.g. "GigaChat-2-Max:8192,gpt-4o-mini:16384".
+		Models map[string]int `env:"GENERATION_MODELS" envSeparator:"," envKeyValSeparator:":"`
+
+		// MaxTokens caps max_tokens for requests without a model.
+		MaxTokens int `env:"GENERATION_MAX_TOKENS" envDefault:"4096"`
 	}
 
 	// ImageTTL controls how long uploaded/generated images are stored in memory.
 	// Example: "10m", "30s".
 	ImageTTL time.Duration `env:"IMAGE_TTL" envDefault:"30s"`
+
+	// ImageReadPolicy is how many times an uploaded image can be downloaded
+	// when the request has no read_policy: "single", "ttl" (until IMAGE_TTL)
+	// or a number.
+	ImageReadPolicy string `env:"IMAGE_READ_POLICY" envDefault:"single"`
+
+	// ImageMaxReads caps the read count clients may ask for (0 — no cap).
+	ImageMaxReads int `env:"IMAGE_MAX_READS" envDefault:"10"`
+
+	// ImageURL signs /api/v1/images/{id} links.
+	ImageURL struct {
+		// HMAC key of new links; empty leaves links unsigned
+		SigningKey string `env:"IMAGE_URL_SIGNING_KEY"`
+
+		// Keys that still verify links during rotation
+		PreviousKeys []string `env:"IMAGE_URL_PREVIOUS_KEYS" envSeparator:","`
+
+		// How long a signed link is valid
+		TTL time.Duration `env:"IMAGE_URL_TTL" envDefault:"10m"`
+
+		// Audience bound into links handed to vision models
+		ModelAudience string `env:"IMAGE_URL_MODEL_AUDIENCE" envDefault:"openai"`
+	}
+
+	// ImageStorage selects where uploaded images are kept.
+	ImageStorage struct {
+		// Backend is "memory", "filesystem" or "s3"
+		Backend string `env:"IMAGE_STORAGE" envDefault:"memory"`
+
+		// Directory for the filesystem backend
+		Dir string `env:"IMAGE_STORAGE_DIR" envDefault:"./data/images"`
+
+		// How often expired images are removed by the filesystem and s3 backends
+		SweepInterval time.Duration `env:"IMAGE_STORAGE_SWEEP_INTERVAL" envDefault:"1m"`
+
+		// Memory backend limits (0 — unlimited)
+		MaxBytes int64 `env:"IMAGE_MEMORY_MAX_BYTES" envDefault:"268435456"`
+
+		MaxEntries int `env:"IMAGE_MEMORY_MAX_ENTRIES" envDefault:"0"`
+
+		// What the memory backend does when full: "lru", "oldest" (evict) or "reject" (503 storage_full)
+		Eviction string `env:"IMAGE_MEMORY_EVICTION" envDefault:"lru"`
+
+		// S3 configures the S3-compatible backend
+		S3 struct {
+			// API root, e.g. https://storage.yandexcloud.net or http://minio:9000
+			Endpoint string `env:"S3_ENDPOINT"`
+
+			Region string `env:"S3_REGION" envDefault:"us-east-1"`
+
+			Bucket string `env:"S3_BUCKET"`
+
+			// Prefix prepended to object keys, e.g. "images/"
+			Prefix string `env:"S3_PREFIX"`
+
+			AccessKeyID string `env:"S3_ACCESS_KEY_ID"`
+
+			SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY"`
+
+			// Address objects as <endpoint>/<bucket>/<key> (needed by MinIO)
+			PathStyle bool `env:"S3_PATH_STYLE" envDefault:"true"`
+
+			// Presign hands vision providers presigned storage links instead of /api/v1/images links
+			Presign bool `env:"S3_PRESIGN" envDefault:"true"`
+
+			// How long presigned links are valid
+			PresignTTL time.Duration `env:"S3_PRESIGN_TTL" envDefault:"5m"`
+		}
+	}
+
+	// ImageDelivery selects how images reach vision models: "url" (link to
+	// /api/v1/images, needs a public BASE_URL, or a presigned S3 link),
+	// "inline" (base64 data: URL) or "auto" (inline when there is no link
+	// a provider can fetch).
+	ImageDelivery string `env:"IMAGE_DELIVERY" envDefault:"auto"`
+
+	// ImageMaxCount limits images per POST /api/v1/chat/image request.
+	ImageMaxCount int `env:"IMAGE_MAX_COUNT" envDefault:"4"`
+
+	// ImageProcessing normalises uploads before they are stored and sent to models.
+	ImageProcessing struct {
+		// Enabled turns on orientation fix, metadata stripping, downscaling and re-encoding
+		Enabled bool `env:"IMAGE_PROCESSING_ENABLED" envDefault:"true"`
+
+		// Longest side in pixels after downscaling (0 keeps the size)
+		MaxDimension int `env:"IMAGE_MAX_DIMENSION" envDefault:"1600"`
+
+		// JPEG re-encoding quality, 1-100
+		JPEGQuality int `env:"IMAGE_JPEG_QUALITY" envDefault:"85"`
+
+		// Uploads with more decoded pixels are rejected
+		MaxPixels int `env:"IMAGE_MAX_PIXELS" envDefault:"50000000"`
+
+		// Transcode converts images to JPEG for providers that do not accept
+		// their format (e.g. WebP for GigaChat); otherwise such providers are skipped
+		Transcode bool `env:"IMAGE_TRANSCODE_UNSUPPORTED" envDefault:"true"`
+	}
+
+	// Upload bounds multipart ingestion of POST /api/v1/chat/image, in bytes.
+	Upload struct {
+		// Single image part
+		MaxImageSize int64 `env:"UPLOAD_MAX_IMAGE_SIZE" envDefault:"10485760"`
+
+		// "text" and "options" parts
+		MaxFieldSize int64 `env:"UPLOAD_MAX_FIELD_SIZE" envDefault:"65536"`
+
+		// All parts of a request together
+		MaxRequestSize int64 `env:"UPLOAD_MAX_REQUEST_SIZE" envDefault:"20971520"`
+	}
+
+	// ModelRequestTimeout bounds model work per API request, including repair
+	// attempts and provider fallbacks (0 disables the deadline).
+	ModelRequestTimeout time.Duration `env:"MODEL_REQUEST_TIMEOUT" envDefault:"2m"`
+
+	// ModelOutputMaxAttempts limits model calls per request when the answer
+	// violates the JSON output contract (1 disables repair).
+	ModelOutputMaxAttempts int `env:"MODEL_OUTPUT_MAX_ATTEMPTS" envDefault:"3"`
+
+	Session struct {
+		// TTL is how long a conversation is kept without new messages.
+		TTL time.Duration `env:"SESSION_TTL" envDefault:"30m"`
+
+		// MaxMessages caps stored history per session (0 — unlimited).
+		MaxMessages int `env:"SESSION_MAX_MESSAGES" envDefault:"20"`
+	}
+
+	// Webhook configures delivery of callbacks to client URLs.
+	Webhook struct {
+		// HMAC key of the X-Webhook-Signature header; empty sends unsigned webhooks
+		Secret string `env:"WEBHOOK_SECRET"`
+
+		// Hosts callbacks may target, exact or "*.example.com"; empty rejects all callbacks
+		AllowedHosts []string `env:"WEBHOOK_ALLOWED_HOSTS" envSeparator:","`
+
+		// Let callbacks reach loopback, private and link-local addresses
+		AllowPrivateNetworks bool `env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" envDefault:"false"`
+
+		// Concurrent deliveries and queued deliveries
+		Workers int `env:"WEBHOOK_WORKERS" envDefault:"4"`
+
+		QueueSize int `env:"WEBHOOK_QUEUE_SIZE" envDefault:"256"`
+
+		// Attempts per delivery, including the first one
+		MaxAttempts int `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"5"`
+
+		// Retry delay before the second attempt, doubled up to MaxBackoff
+		InitialBackoff time.Duration `env:"WEBHOOK_INITIAL_BACKOFF" envDefault:"1s"`
+
+		MaxBackoff time.Duration `env:"WEBHOOK_MAX_BACKOFF" envDefault:"1m"`
+
+		// Timeout of a single attempt
+		Timeout time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"5s"`
+
+		// Deliveries kept in the log of /api/v1/admin/webhooks/deliveries
+		LogSize int `env:"WEBHOOK_LOG_SIZE" envDefault:"1000"`
+	}
+
+	// Job configures asynchronous image analysis of /api/v1/jobs.
+	Job struct {
+		// Concurrent vision calls and jobs waiting for a worker
+		Workers int `env:"JOB_WORKERS" envDefault:"4"`
+
+		QueueSize int `env:"JOB_QUEUE_SIZE" envDefault:"64"`
+
+		// TTL is how long a job is kept after its last update.
+		TTL time.Duration `env:"JOB_TTL" envDefault:"1h"`
+	}
+
+	// Batch configures /api/v1/batches, served by the first text provider
+	// that processes batches (GigaChat).
+	Batch struct {
+		// Prompts accepted in a single batch
+		MaxPrompts int `env:"BATCH_MAX_PROMPTS" envDefault:"1000"`
+
+		// How often submitted batches are checked upstream
+		PollInterval time.Duration `env:"BATCH_POLL_INTERVAL" envDefault:"30s"`
+
+		// TTL is how long a batch is kept after its last update.
+		TTL time.Duration `env:"BATCH_TTL" envDefault:"24h"`
+	}
+
+	// AdminToken is the bearer token of /api/v1/admin endpoints; empty keeps them closed.
+	AdminToken string `env:"ADMIN_TOKEN"`
 }
 
-func isModelAllowed(model string) bool {
+// IsGigachatModel reports whether the model is a supported GigaChat model.
+func IsGigachatModel(model string) bool {
 	switch model {
 	case "GigaChat-2":
 		return true
@@ -83,10 +322,242 @@ func Load() (Config, error) {
 		return Config{}, fmt.Errorf("parse env: %w", err)
 	}
 
-	// Validate model value
-	if !isModelAllowed(cfg.Gigachat.Model) {
-		return Config{}, fmt.Errorf("invalid GIGACHAT_MODEL: %q (allowed: GigaChat-2, GigaChat-2-Pro, GigaChat-2-Max)", cfg.Gigachat.Model)
+	if err := cfg.validateProviders(); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.validateGeneration(); err != nil {
+		return Config{}, err
+	}
+	switch cfg.ImageDelivery {
+	case "auto", "url", "inline":
+	default:
+		return Config{}, fmt.Errorf("invalid IMAGE_DELIVERY: %q (allowed: auto, url, inline)", cfg.ImageDelivery)
+	}
+	switch cfg.ImageStorage.Backend {
+	case "memory":
+		switch cfg.ImageStorage.Eviction {
+		case "lru", "oldest", "reject":
+		default:
+			return Config{}, fmt.Errorf("invalid IMAGE_MEMORY_EVICTION: %q (allowed: lru, oldest, reject)", cfg.ImageStorage.Eviction)
+		}
+		if cfg.ImageStorage.MaxBytes < 0 || cfg.ImageStorage.MaxEntries < 0 {
+			return Config{}, fmt.Errorf("IMAGE_MEMORY_MAX_BYTES and IMAGE_MEMORY_MAX_ENTRIES should not be negative")
+		}
+	case "filesystem":
+	case "s3":
+		if cfg.ImageStorage.S3.Endpoint == "" || cfg.ImageStorage.S3.Bucket == "" {
+			return Config{}, fmt.Errorf("S3_ENDPOINT and S3_BUCKET are required for IMAGE_STORAGE=s3")
+		}
+		if cfg.ImageStorage.S3.AccessKeyID == "" || cfg.ImageStorage.S3.SecretAccessKey == "" {
+			return Config{}, fmt.Errorf("S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for IMAGE_STORAGE=s3")
+		}
+	default:
+		return Config{}, fmt.Errorf("invalid IMAGE_STORAGE: %q (allowed: memory, filesystem, s3)", cfg.ImageStorage.Backend)
+	}
+	if err := cfg.validateReadPolicy(); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.validateImageURL(); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.validateWebhook(); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.validateJob(); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.validateBatch(); err != nil {
+		return Config{}, err
+	}
+	if cfg.ImageMaxCount <= 0 {
+		return Config{}, fmt.Errorf("invalid IMAGE_MAX_COUNT: %d (should be positive)", cfg.ImageMaxCount)
+	}
+	if cfg.ImageProcessing.MaxDimension < 0 {
+		return Config{}, fmt.Errorf("invalid IMAGE_MAX_DIMENSION: %d (should not be negative)", cfg.ImageProcessing.MaxDimension)
+	}
+	if cfg.ImageProcessing.JPEGQuality < 1 || cfg.ImageProcessing.JPEGQuality > 100 {
+		return Config{}, fmt.Errorf("invalid IMAGE_JPEG_QUALITY: %d (allowed: 1-100)", cfg.ImageProcessing.JPEGQuality)
+	}
+	for name, limit := range map[string]int64{
+		"UPLOAD_MAX_IMAGE_SIZE":   cfg.Upload.MaxImageSize,
+		"UPLOAD_MAX_FIELD_SIZE":   cfg.Upload.MaxFieldSize,
+		"UPLOAD_MAX_REQUEST_SIZE": cfg.Upload.MaxRequestSize,
+	} {
+		if limit <= 0 {
+			return Config{}, fmt.Errorf("invalid %s: %d (should be positive)", name, limit)
+		}
 	}
 
 	return cfg, nil
 }
+
+// GenerationParams lists generation options of the public API.
+var GenerationParams = []string{"model", "temperature", "top_p", "max_tokens", "repetition_penalty"}
+
+// validateGeneration checks the generation allowlist and limits.
+func (c *Config) validateGeneration() error {
+	params := make([]string, 0, len(c.Generation.AllowedParams))
+	for _, param := range c.Generation.AllowedParams {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		if !slices.Contains(GenerationParams, param) {
+			return fmt.Errorf("invalid GENERATION_ALLOWED_PARAMS: unknown option %q (allowed: %s)", param, strings.Join(GenerationParams, ", "))
+		}
+		params = append(params, param)
+	}
+	c.Generation.AllowedParams = params
+
+	for model, limit := range c.Generation.Models {
+		if limit <= 0 {
+			return fmt.Errorf("invalid GENERATION_MODELS: max_tokens limit of %q should be positive", model)
+		}
+	}
+	if c.Generation.MaxTokens <= 0 {
+		return fmt.Errorf("GENERATION_MAX_TOKENS should be positive")
+	}
+	return nil
+}
+
+// validateReadPolicy checks the default image read policy.
+func (c Config) validateReadPolicy() error {
+	if c.ImageMaxReads < 0 {
+		return fmt.Errorf("invalid IMAGE_MAX_READS: %d (should not be negative)", c.ImageMaxReads)
+	}
+	switch c.ImageReadPolicy {
+	case "single", "ttl":
+		return nil
+	}
+	reads, err := strconv.Atoi(c.ImageReadPolicy)
+	if err != nil || reads < 1 || (c.ImageMaxReads > 0 && reads > c.ImageMaxReads) {
+		return fmt.Errorf("invalid IMAGE_READ_POLICY: %q (allowed: single, ttl or 1-IMAGE_MAX_READS)", c.ImageReadPolicy)
+	}
+	return nil
+}
+
+// minSigningKeyLen is the shortest accepted image link signing key.
+const minSigningKeyLen = 32
+
+// validateImageURL checks the image link signing keys.
+func (c Config) validateImageURL() error {
+	if c.ImageURL.SigningKey == "" {
+		if len(c.ImageURL.PreviousKeys) != 0 {
+			return fmt.Errorf("IMAGE_URL_PREVIOUS_KEYS needs IMAGE_URL_SIGNING_KEY")
+		}
+		return nil
+	}
+	for _, key := range append([]string{c.ImageURL.SigningKey}, c.ImageURL.PreviousKeys...) {
+		if len(key) < minSigningKeyLen {
+			return fmt.Errorf("image URL signing keys should be at least %d bytes long", minSigningKeyLen)
+		}
+	}
+	if c.ImageURL.TTL <= 0 {
+		return fmt.Errorf("invalid IMAGE_URL_TTL: %s (should be positive)", c.ImageURL.TTL)
+	}
+	return nil
+}
+
+// validateWebhook checks webhook delivery limits.
+func (c Config) validateWebhook() error {
+	for name, value := range map[string]int{
+		"WEBHOOK_WORKERS":      c.Webhook.Workers,
+		"WEBHOOK_QUEUE_SIZE":   c.Webhook.QueueSize,
+		"WEBHOOK_MAX_ATTEMPTS": c.Webhook.MaxAttempts,
+		"WEBHOOK_LOG_SIZE":     c.Webhook.LogSize,
+	} {
+		if value <= 0 {
+			return fmt.Errorf("invalid %s: %d (should be positive)", name, value)
+		}
+	}
+	for name, value := range map[string]time.Duration{
+		"WEBHOOK_INITIAL_BACKOFF": c.Webhook.InitialBackoff,
+		"WEBHOOK_MAX_BACKOFF":     c.Webhook.MaxBackoff,
+		"WEBHOOK_TIMEOUT":         c.Webhook.Timeout,
+	} {
+		if value <= 0 {
+			return fmt.Errorf("invalid %s: %s (should be positive)", name, value)
+		}
+	}
+	return nil
+}
+
+// validateJob checks the image job worker pool.
+func (c Config) validateJob() error {
+	if c.Job.Workers <= 0 {
+		return fmt.Errorf("invalid JOB_WORKERS: %d (should be positive)", c.Job.Workers)
+	}
+	if c.Job.QueueSize <= 0 {
+		return fmt.Errorf("invalid JOB_QUEUE_SIZE: %d (should be positive)", c.Job.QueueSize)
+	}
+	if c.Job.TTL <= 0 {
+		return fmt.Errorf("invalid JOB_TTL: %s (should be positive)", c.Job.TTL)
+	}
+	return nil
+}
+
+// validateBatch checks batch limits.
+func (c Config) validateBatch() error {
+	if c.Batch.MaxPrompts <= 0 {
+		return fmt.Errorf("invalid BATCH_MAX_PROMPTS: %d (should be positive)", c.Batch.MaxPrompts)
+	}
+	for name, value := range map[string]time.Duration{
+		"BATCH_POLL_INTERVAL": c.Batch.PollInterval,
+		"BATCH_TTL":           c.Batch.TTL,
+	} {
+		if value <= 0 {
+			return fmt.Errorf("invalid %s: %s (should be positive)", name, value)
+		}
+	}
+	return nil
+}
+
+// UsesProvider reports whether the provider is selected for any capability.
+func (c Config) UsesProvider(name string) bool {
+	return slices.Contains(c.Providers.Text, name) || slices.Contains(c.Providers.Vision, name)
+}
+
+// validateProviders checks settings required by the selected providers only.
+func (c *Config) validateProviders() error {
+	lists := map[string]*[]string{"TEXT_PROVIDER": &c.Providers.Text, "VISION_PROVIDER": &c.Providers.Vision}
+	for variable, list := range lists {
+		names := make([]string, 0, len(*list))
+		for _, name := range *list {
+			name = strings.TrimSpace(name)
+			switch name {
+			case "gigachat", "openai", "fake":
+			default:
+				return fmt.Errorf("invalid %s: unknown provider %q (allowed: gigachat, openai, fake)", variable, name)
+			}
+			if slices.Contains(names, name) {
+				return fmt.Errorf("invalid %s: provider %q is listed twice", variable, name)
+			}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("%s should list at least one provider", variable)
+		}
+		*list = names
+	}
+
+	if c.UsesProvider("gigachat") {
+		if c.Gigachat.BasicKey == "" {
+			return fmt.Errorf("GIGACHAT_BASIC_KEY is required for the gigachat provider")
+		}
+		// Validate model value
+		if !IsGigachatModel(c.Gigachat.Model) {
+			return fmt.Errorf("invalid GIGACHAT_MODEL: %q (allowed: GigaChat-2, GigaChat-2-Pro, GigaChat-2-Max)", c.Gigachat.Model)
+		}
+	}
+
+	if c.UsesProvider("openai") {
+		if c.OpenAI.BasicKey == "" {
+			return fmt.Errorf("OPENAI_BASIC_KEY is required for the openai provider")
+		}
+		if c.OpenAI.Model == "" {
+			return fmt.Errorf("OPENAI_MODEL is required for the openai provider")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package fashion
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MinItems is the minimal number of items the model must return.
+const MinItems = 5
+
+// RefusalNotFashion is returned by the model for requests unrelated to clothing.
+const RefusalNotFashion = "not_fashion_related"
+
+// fields lists the exact set of item fields required by the contract.
+var fields = []string{
+	"category", "style", "fit", "layer", "formality",
+	"gender", "season", "temperature", "colors", "materials",
+}
+
+// Problem describes a single violation of the output contract.
+type Problem struct {
+	// Item is a zero-based item index, or -1 when the whole response is affected.
+	Item int `json:"item"`
+	// Field is the offending field name, empty for item or response level problems.
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	switch {
+	case p.Item < 0:
+		return p.Message
+	case p.Field == "":
+		return fmt.Sprintf("item %d: %s", p.Item, p.Message)
+	default:
+		return fmt.Sprintf("item %d: %s: %s", p.Item, p.Field, p.Message)
+	}
+}
+
+// Result is a parsed model answer.
+type Result struct {
+	// Items contains only the items that passed validation.
+	Items []Item `json:"items"`
+	// Problems lists every contract violation found in the answer.
+	Problems []Problem `json:"problems"`
+	// Refusal is set when the model declined the request (e.g. not_fashion_related).
+	Refusal string `json:"refusal,omitempty"`
+}
+
+// Valid reports whether the answer fully follows the contract.
+// A refusal is a valid answer.
+func (r Result) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// StripFences removes surrounding whitespace and a markdown code fence
+// (with an optional language tag, e.g. ```json) from the model output.
+func StripFences(message string) string {
+	s := strings.TrimSpace(message)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	s = strings.TrimPrefix(s, "```")
+	if i := strings.IndexByte(s, '\n'); i >= 0 && isFenceTag(s[:i]) {
+		s = s[i+1:]
+	}
+	s = strings.TrimSpace(s)
+	return strings.TrimSpace(strings.TrimSuffix(s, "```"))
+}
+
+func isFenceTag(s string) bool {
+	s = strings.TrimSpace(s)
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse decodes the raw model output and validates it against the contract.
+// Text around the JSON array is tolerated; everything else is reported in Problems.
+func Parse(raw string) Result {
+	var result Result
+
+	text := StripFences(raw)
+	start := strings.IndexByte(text, '[')
+	end := strings.LastIndexByte(text, ']')
+	if start < 0 || end < start {
+		result.Problems = append(result.Problems, Problem{Item: -1, Message: "response is not a JSON array"})
+		return result
+	}
+
+	var rawItems []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(text[start:end+1]), &rawItems); err != nil {
+		result.Problems = append(result.Problems, Problem{Item: -1, Message: "invalid JSON array of objects: " + err.Error()})
+		return result
+	}
+
+	if refusal, ok := parseRefusal(rawItems); ok {
+		result.Refusal = refusal
+		return result
+	}
+
+	for i := range rawItems {
+		item, problems := parseItem(i, rawItems[i])
+		if len(problems) > 0 {
+			result.Problems = append(result.Problems, problems...)
+			continue
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	if len(rawItems) < MinItems {
+		result.Problems = append(result.Problems, Problem{
+			Item:    -1,
+			Message: fmt.Sprintf("expected at least %d items, got %d", MinItems, len(rawItems)),
+		})
+	}
+
+	return result
+}
+
+// parseRefusal detects the [{"error":"..."}] answer.
+func parseRefusal(rawItems []map[string]json.RawMessage) (string, bool) {
+	if len(rawItems) != 1 || len(rawItems[0]) != 1 {
+		return "", false
+	}
+	rawErr, ok := rawItems[0]["error"]
+	if !ok {
+		return "", false
+	}
+	var refusal string
+	if err := json.Unmarshal(rawErr, &refusal); err != nil || refusal == "" {
+		return "", false
+	}
+	return refusal, true
+}
+
+func parseItem(index int, raw map[string]json.RawMessage) (Item, []Problem) {
+	var problems []Problem
+	report := func(field, format string, args ...any) {
+		problems = append(problems, Problem{Item: index, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, field := range fields {
+		if _, ok := raw[field]; !ok {
+			report(field, "missing field")
+		}
+	}
+	unexpected := make([]string, 0)
+	for field := range raw {
+		if !slices.Contains(fields, field) {
+			unexpected = append(unexpected, field)
+		}
+	}
+	slices.Sort(unexpected)
+	for _, field := range unexpected {
+		report(field, "unexpected field")
+	}
+
+	var item Item
+	item.Category = parseEnum(raw, "category", Category.Valid, report)
+	item.Style = parseEnum(raw, "style", Style.Valid, report)
+	item.Fit = parseEnum(raw, "fit", Fit.Valid, report)
+	item.Layer = parseEnum(raw, "layer", Layer.Valid, report)
+	item.Formality = parseEnum(raw, "formality", Formality.Valid, report)
+	item.Gender = parseEnum(raw, "gender", Gender.Valid, report)
+	item.Season = parseEnum(raw, "season", Season.Valid, report)
+	item.Temperature = parseEnum(raw, "temperature", Temperature.Valid, report)
+	item.Colors = parseEnumList(raw, "colors", Color.Valid, report)
+	item.Materials = parseEnumList(raw, "materials", Material.Valid, report)
+
+	if _, ok := raw["colors"]; ok && len(item.Colors) == 0 {
+		report("colors", "at least one color is required")
+	}
+
+	return item, problems
+}
+
+type reportFunc func(field, format string, args ...any)
+
+// parseEnum decodes a string field and checks it against the vocabulary.
+// Missing fields are reported by the caller.
+func parseEnum[T ~string](raw map[string]json.RawMessage, field string, valid func(T) bool, report reportFunc) T {
+	value, ok := raw[field]
+	if !ok {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		report(field, "expected string, got %s", value)
+		return ""
+	}
+	v := T(s)
+	if !valid(v) {
+		report(field, "unknown value %q", s)
+	}
+	return v
+}
+
+// parseEnumList decodes a list of strings and checks each one against the vocabulary.
+func parseEnumList[T ~string](raw map[string]json.RawMessage, field string, valid func(T) bool, report reportFunc) []T {
+	value, ok := raw[field]
+	if !ok {
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(value, &list); err != nil {
+		report(field, "expected array of strings, got %s", value)
+		return nil
+	}
+	out := make([]T, 0, len(list))
+	for _, s := range list {
+		v := T(s)
+		if !valid(v) {
+			report(field, "unknown value %q", s)
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
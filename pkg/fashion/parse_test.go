@@ -0,0 +1,73 @@
+package fashion_test
+
+import (
+	"strings"
+	"testing"
+
+	"pod_api/pkg/fashion"
+
+	"github.com/stretchr/testify/require"
+)
+
+const validItem = `{"category":"jeans","style":"casual","fit":"regular","layer":"base","formality":"casual","gender":"unisex","season":"all_seasons","temperature":"mild","colors":["denim"],"materials":["denim","cotton"]}`
+
+func repeatItems(item string, n int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = item
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+func TestStripFences(t *testing.T) {
+	require.Equal(t, "[]", fashion.StripFences("```json\n[]\n```"))
+	require.Equal(t, "[]", fashion.StripFences("  ```\n[]\n```\n"))
+	require.Equal(t, "[1]", fashion.StripFences("```[1]```"))
+	require.Equal(t, "[]", fashion.StripFences("[]"))
+}
+
+func TestParseValid(t *testing.T) {
+	result := fashion.Parse("```json\n" + repeatItems(validItem, fashion.MinItems) + "\n```")
+
+	require.True(t, result.Valid(), result.Problems)
+	require.Len(t, result.Items, fashion.MinItems)
+	require.Equal(t, fashion.CategoryJeans, result.Items[0].Category)
+	require.Equal(t, []fashion.Material{"denim", "cotton"}, result.Items[0].Materials)
+}
+
+func TestParseRefusal(t *testing.T) {
+	result := fashion.Parse(`[{"error":"not_fashion_related"}]`)
+
+	require.True(t, result.Valid())
+	require.Equal(t, fashion.RefusalNotFashion, result.Refusal)
+	require.Empty(t, result.Items)
+}
+
+func TestParseProblems(t *testing.T) {
+	invalid := strings.Replace(validItem, `"jeans"`, `"pullover"`, 1)
+	invalid = strings.Replace(invalid, `"fit":"regular",`, ``, 1)
+	invalid = strings.Replace(invalid, `{`, `{"name":"x",`, 1)
+
+	result := fashion.Parse("[" + validItem + "," + invalid + "]")
+	require.False(t, result.Valid())
+	require.Len(t, result.Items, 1)
+
+	var messages []string
+	for _, p := range result.Problems {
+		messages = append(messages, p.String())
+	}
+	require.Equal(t, []string{
+		"item 1: fit: missing field",
+		"item 1: name: unexpected field",
+		`item 1: category: unknown value "pullover"`,
+		"expected at least 5 items, got 2",
+	}, messages)
+}
+
+func TestParseNotJSON(t *testing.T) {
+	result := fashion.Parse("Конечно! Вот подборка одежды.")
+
+	require.False(t, result.Valid())
+	require.Len(t, result.Problems, 1)
+	require.Equal(t, -1, result.Problems[0].Item)
+}
@@ -0,0 +1,211 @@
+// Package fashion describes the fashion item contract that models must follow
+// (see prompting.SystemPrompt) and validates raw model output against it.
+package fashion
+
+import "slices"
+
+// Category is a clothing item category.
+type Category string
+
+const (
+	CategoryOuterwear Category = "outerwear"
+	CategoryCoat      Category = "coat"
+	CategoryJacket    Category = "jacket"
+	CategoryBlazer    Category = "blazer"
+	CategoryVest      Category = "vest"
+	CategoryCardigan  Category = "cardigan"
+	CategorySweater   Category = "sweater"
+	CategoryHoodie    Category = "hoodie"
+	CategoryShirt     Category = "shirt"
+	CategoryTshirt    Category = "tshirt"
+	CategoryTop       Category = "top"
+	CategoryDress     Category = "dress"
+	CategorySkirt     Category = "skirt"
+	CategoryPants     Category = "pants"
+	CategoryJeans     Category = "jeans"
+	CategoryShorts    Category = "shorts"
+	CategorySuit      Category = "suit"
+	CategoryOverall   Category = "overall"
+	CategoryUnderwear Category = "underwear"
+	CategorySocks     Category = "socks"
+	CategoryTights    Category = "tights"
+	CategoryShoes     Category = "shoes"
+	CategorySneakers  Category = "sneakers"
+	CategoryBoots     Category = "boots"
+	CategorySandals   Category = "sandals"
+	CategoryHeels     Category = "heels"
+	CategorySlippers  Category = "slippers"
+	CategoryBelt      Category = "belt"
+	CategoryScarf     Category = "scarf"
+	CategoryHat       Category = "hat"
+	CategoryCap       Category = "cap"
+	CategoryBeanie    Category = "beanie"
+	CategoryGloves    Category = "gloves"
+	CategoryMittens   Category = "mittens"
+	CategoryBag       Category = "bag"
+	CategoryBackpack  Category = "backpack"
+	CategoryWatch     Category = "watch"
+	CategoryBracelet  Category = "bracelet"
+	CategoryNecklace  Category = "necklace"
+	CategoryEarrings  Category = "earrings"
+	CategoryRing      Category = "ring"
+	CategoryAccessory Category = "accessory"
+)
+
+// Categories lists all allowed categories.
+var Categories = []Category{
+	CategoryOuterwear, CategoryCoat, CategoryJacket, CategoryBlazer, CategoryVest,
+	CategoryCardigan, CategorySweater, CategoryHoodie, CategoryShirt, CategoryTshirt,
+	CategoryTop, CategoryDress, CategorySkirt, CategoryPants, CategoryJeans,
+	CategoryShorts, CategorySuit, CategoryOverall, CategoryUnderwear, CategorySocks,
+	CategoryTights, CategoryShoes, CategorySneakers, CategoryBoots, CategorySandals,
+	CategoryHeels, CategorySlippers, CategoryBelt, CategoryScarf, CategoryHat,
+	CategoryCap, CategoryBeanie, CategoryGloves, CategoryMittens, CategoryBag,
+	CategoryBackpack, CategoryWatch, CategoryBracelet, CategoryNecklace, CategoryEarrings,
+	CategoryRing, CategoryAccessory,
+}
+
+// Style is the overall style of an item.
+type Style string
+
+const (
+	StyleCasual     Style = "casual"
+	StyleClassic    Style = "classic"
+	StyleSport      Style = "sport"
+	StyleStreet     Style = "street"
+	StyleBusiness   Style = "business"
+	StyleRomantic   Style = "romantic"
+	StyleTravel     Style = "travel"
+	StyleHome       Style = "home"
+	StyleParty      Style = "party"
+	StyleFormal     Style = "formal"
+	StyleMinimalist Style = "minimalist"
+	StyleOther      Style = "other"
+)
+
+// Styles lists all allowed styles.
+var Styles = []Style{
+	StyleCasual, StyleClassic, StyleSport, StyleStreet, StyleBusiness, StyleRomantic,
+	StyleTravel, StyleHome, StyleParty, StyleFormal, StyleMinimalist, StyleOther,
+}
+
+// Fit is how an item sits on the body.
+type Fit string
+
+const (
+	FitSlim      Fit = "slim"
+	FitRegular   Fit = "regular"
+	FitOversized Fit = "oversized"
+	FitLoose     Fit = "loose"
+)
+
+// Fits lists all allowed fits.
+var Fits = []Fit{FitSlim, FitRegular, FitOversized, FitLoose}
+
+// Layer is the position of an item in an outfit.
+type Layer string
+
+const (
+	LayerBase      Layer = "base"
+	LayerMid       Layer = "mid"
+	LayerOuter     Layer = "outer"
+	LayerAccessory Layer = "accessory"
+)
+
+// Layers lists all allowed layers.
+var Layers = []Layer{LayerBase, LayerMid, LayerOuter, LayerAccessory}
+
+// Formality is the dress code level of an item.
+type Formality string
+
+const (
+	FormalityCasual Formality = "casual"
+	FormalitySmart  Formality = "smart"
+	FormalityFormal Formality = "formal"
+)
+
+// Formalities lists all allowed formality levels.
+var Formalities = []Formality{FormalityCasual, FormalitySmart, FormalityFormal}
+
+// Gender is the intended wearer of an item.
+type Gender string
+
+const (
+	GenderMale    Gender = "male"
+	GenderFemale  Gender = "female"
+	GenderUnisex  Gender = "unisex"
+	GenderUnknown Gender = "unknown"
+)
+
+// Genders lists all allowed genders.
+var Genders = []Gender{GenderMale, GenderFemale, GenderUnisex, GenderUnknown}
+
+// Season is when an item is worn.
+type Season string
+
+const (
+	SeasonWinter     Season = "winter"
+	SeasonSpring     Season = "spring"
+	SeasonSummer     Season = "summer"
+	SeasonAutumn     Season = "autumn"
+	SeasonAllSeasons Season = "all_seasons"
+)
+
+// Seasons lists all allowed seasons.
+var Seasons = []Season{SeasonWinter, SeasonSpring, SeasonSummer, SeasonAutumn, SeasonAllSeasons}
+
+// Temperature is the weather an item suits.
+type Temperature string
+
+const (
+	TemperatureCold Temperature = "cold"
+	TemperatureMild Temperature = "mild"
+	TemperatureWarm Temperature = "warm"
+	TemperatureHot  Temperature = "hot"
+)
+
+// Temperatures lists all allowed temperatures.
+var Temperatures = []Temperature{TemperatureCold, TemperatureMild, TemperatureWarm, TemperatureHot}
+
+// Color is one of the main item colors.
+type Color string
+
+// Colors lists all allowed colors.
+var Colors = []Color{
+	"black", "white", "grey", "blue", "red", "beige", "brown", "green", "navy",
+	"olive", "mustard", "burgundy", "cream", "khaki", "sand", "tan", "denim", "pastel",
+}
+
+// Material is one of the item fabrics.
+type Material string
+
+// Materials lists all allowed materials.
+var Materials = []Material{
+	"cotton", "wool", "polyester", "linen", "silk", "leather", "denim",
+	"nylon", "cashmere", "viscose", "suede", "acrylic", "elastane", "rubber",
+}
+
+func (v Category) Valid() bool    { return slices.Contains(Categories, v) }
+func (v Style) Valid() bool       { return slices.Contains(Styles, v) }
+func (v Fit) Valid() bool         { return slices.Contains(Fits, v) }
+func (v Layer) Valid() bool       { return slices.Contains(Layers, v) }
+func (v Formality) Valid() bool   { return slices.Contains(Formalities, v) }
+func (v Gender) Valid() bool      { return slices.Contains(Genders, v) }
+func (v Season) Valid() bool      { return slices.Contains(Seasons, v) }
+func (v Temperature) Valid() bool { return slices.Contains(Temperatures, v) }
+func (v Color) Valid() bool       { return slices.Contains(Colors, v) }
+func (v Material) Valid() bool    { return slices.Contains(Materials, v) }
+
+// Item is a single clothing item as described by the model.
+type Item struct {
+	Category    Category    `json:"category"`
+	Style       Style       `json:"style"`
+	Fit         Fit         `json:"fit"`
+	Layer       Layer       `json:"layer"`
+	Formality   Formality   `json:"formality"`
+	Gender      Gender      `json:"gender"`
+	Season      Season      `json:"season"`
+	Temperature Temperature `json:"temperature"`
+	Colors      []Color     `json:"colors"`
+	Materials   []Material  `json:"materials"`
+}
@@ -0,0 +1,29 @@
+package imaging
+
+import "bytes"
+
+// Supported image content types.
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+	TypeWebP = "image/webp"
+)
+
+// DetectContentType identifies a supported image by its magic bytes and
+// returns "" for anything else. Unlike http.DetectContentType it checks the
+// complete signatures, so truncated or disguised data is not accepted.
+func DetectContentType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xFF\xD8\xFF")):
+		return TypeJPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return TypePNG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return TypeGIF
+	case len(data) >= 16 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")) &&
+		(bytes.Equal(data[12:16], []byte("VP8 ")) || bytes.Equal(data[12:16], []byte("VP8L")) || bytes.Equal(data[12:16], []byte("VP8X"))):
+		return TypeWebP
+	}
+	return ""
+}
@@ -0,0 +1,164 @@
+// Package imaging normalises uploaded images before they are stored and
+// sent to vision models: it applies the EXIF orientation, strips metadata,
+// downscales large photos and re-encodes them. PNG, JPEG, GIF and WebP
+// are decoded.
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+
+	_ "golang.org/x/image/webp"
+	"pod_api/pkg/metrics"
+	"pod_api/pkg/models"
+)
+
+// ErrInvalidImage is returned for data that cannot be decoded as a
+// supported image or exceeds MaxPixels.
+var ErrInvalidImage = errors.New("invalid image")
+
+// Options configures Processor.
+type Options struct {
+	// MaxDimension limits the longest side in pixels (0 keeps the size).
+	MaxDimension int
+	// JPEGQuality is used when re-encoding JPEG images (1-100).
+	JPEGQuality int
+	// MaxPixels rejects images whose decoded size would exceed it,
+	// protecting against decompression bombs (0 disables the check).
+	MaxPixels int
+
+	// Metrics is optional; nil disables processing metrics.
+	Metrics *metrics.Registry
+}
+
+// NewOptions returns sensible defaults.
+func NewOptions() Options {
+	return Options{
+		MaxDimension: 1600,
+		JPEGQuality:  85,
+		MaxPixels:    50_000_000,
+	}
+}
+
+// Processor normalises and transcodes images.
+type Processor struct {
+	opts Options
+}
+
+// NewProcessor constructs Processor.
+func NewProcessor(opts Options) *Processor {
+	if opts.JPEGQuality <= 0 || opts.JPEGQuality > 100 {
+		opts.JPEGQuality = jpeg.DefaultQuality
+	}
+	return &Processor{opts: opts}
+}
+
+// Process decodes the image, applies its EXIF orientation, downscales it
+// to MaxDimension and re-encodes it. JPEG and PNG keep their format; GIF
+// (first frame) becomes PNG, WebP becomes JPEG, or PNG when it has
+// transparency. Re-encoding drops all metadata (EXIF, GPS, text chunks).
+// Only ContentType and Data of the image are used.
+func (p *Processor) Process(ctx context.Context, img models.Image) (models.Image, error) {
+	rgba, format, err := p.decode(ctx, img.Data)
+	if err != nil {
+		return models.Image{}, err
+	}
+
+	orientation := orientationNormal
+	if format == "jpeg" {
+		orientation = jpegOrientation(img.Data)
+	}
+
+	// Downscale before orienting: it is cheaper on the smaller image and
+	// the limit applies to both sides anyway.
+	bounds := rgba.Bounds()
+	width, height := fitSize(bounds.Dx(), bounds.Dy(), p.opts.MaxDimension)
+	resized := width != bounds.Dx() || height != bounds.Dy()
+	rgba = orient(downscale(rgba, width, height), orientation)
+
+	contentType := TypePNG
+	if format == "jpeg" || (format == "webp" && rgba.Opaque()) {
+		contentType = TypeJPEG
+	}
+	out, err := p.encode(rgba, contentType)
+	if err != nil {
+		return models.Image{}, err
+	}
+
+	p.record(ctx, format, resized, len(img.Data), len(out.Data))
+	return out, nil
+}
+
+// ToJPEG converts an image to JPEG for providers that do not accept its
+// format; transparent areas are flattened onto white. It implements
+// providers.Transcoder.
+func (p *Processor) ToJPEG(ctx context.Context, img models.Image) (models.Image, error) {
+	rgba, _, err := p.decode(ctx, img.Data)
+	if err != nil {
+		return models.Image{}, err
+	}
+	if !rgba.Opaque() {
+		flat := image.NewRGBA(rgba.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), rgba, image.Point{}, draw.Over)
+		rgba = flat
+	}
+	return p.encode(rgba, TypeJPEG)
+}
+
+// decode returns the first frame of a supported image as RGBA along with
+// the format name registered in package image.
+func (p *Processor) decode(ctx context.Context, data []byte) (*image.RGBA, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	if DetectContentType(data) == "" {
+		return nil, "", fmt.Errorf("%w: unsupported format", ErrInvalidImage)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if p.opts.MaxPixels > 0 && config.Width*config.Height > p.opts.MaxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrInvalidImage, config.Width, config.Height, p.opts.MaxPixels)
+	}
+	// gif.Decode returns the first frame of an animation.
+	decoded, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	return toRGBA(decoded), format, nil
+}
+
+func (p *Processor) encode(rgba *image.RGBA, contentType string) (models.Image, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == TypeJPEG {
+		err = jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: p.opts.JPEGQuality})
+	} else {
+		err = png.Encode(&buf, rgba)
+	}
+	if err != nil {
+		return models.Image{}, err
+	}
+	return models.Image{ContentType: contentType, Data: buf.Bytes()}, nil
+}
+
+func (p *Processor) record(ctx context.Context, format string, resized bool, original, processed int) {
+	if p.opts.Metrics == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	p.opts.Metrics.Inc(ctx, "images_processed_total", map[string]string{"format": format, "resized": strconv.FormatBool(resized)}, 1)
+	p.opts.Metrics.Inc(ctx, "image_bytes_total", map[string]string{"format": format, "stage": "original"}, int64(original))
+	p.opts.Metrics.Inc(ctx, "image_bytes_total", map[string]string{"format": format, "stage": "processed"}, int64(processed))
+}
@@ -0,0 +1,148 @@
+package imaging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"pod_api/pkg/imaging"
+	"pod_api/pkg/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testImage is red in the left half and blue in the right half.
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF APP1 segment with the orientation tag
+// right after the JPEG SOI marker.
+func withOrientation(jpegData []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // header, IFD0 at 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // orientation SHORT
+		0, 0, 0, 0, // no next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	size := len(payload) + 2
+	segment := append([]byte{0xFF, 0xE1, byte(size >> 8), byte(size)}, payload...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestProcessJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(400, 200), nil))
+	// Rotate 90° clockwise to display upright.
+	original := withOrientation(buf.Bytes(), 6)
+
+	opts := imaging.NewOptions()
+	opts.MaxDimension = 100
+	out, err := imaging.NewProcessor(opts).Process(context.Background(), models.Image{ContentType: "image/jpeg", Data: original})
+	require.NoError(t, err)
+	require.Equal(t, "image/jpeg", out.ContentType)
+	require.NotContains(t, string(out.Data), "Exif")
+
+	img, err := jpeg.Decode(bytes.NewReader(out.Data))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 50, 100), img.Bounds())
+	// The left (red) half ends up on top.
+	r, _, b, _ := img.At(25, 10).RGBA()
+	require.Greater(t, r, b)
+	r, _, b, _ = img.At(25, 90).RGBA()
+	require.Greater(t, b, r)
+}
+
+func TestProcessPNGKeepsSmallImages(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(40, 20)))
+
+	out, err := imaging.NewProcessor(imaging.NewOptions()).Process(context.Background(), models.Image{ContentType: "image/png", Data: buf.Bytes()})
+	require.NoError(t, err)
+	require.Equal(t, "image/png", out.ContentType)
+
+	config, err := png.DecodeConfig(bytes.NewReader(out.Data))
+	require.NoError(t, err)
+	require.Equal(t, 40, config.Width)
+	require.Equal(t, 20, config.Height)
+}
+
+func TestProcessRejects(t *testing.T) {
+	processor := imaging.NewProcessor(imaging.NewOptions())
+	_, err := processor.Process(context.Background(), models.Image{ContentType: "image/png", Data: []byte("\x89PNG\r\n\x1a\nbroken")})
+	require.ErrorIs(t, err, imaging.ErrInvalidImage)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(40, 20)))
+	opts := imaging.NewOptions()
+	opts.MaxPixels = 100
+	_, err = imaging.NewProcessor(opts).Process(context.Background(), models.Image{ContentType: "image/png", Data: buf.Bytes()})
+	require.ErrorIs(t, err, imaging.ErrInvalidImage)
+}
+
+// transparentWebP is a lossless 1x1 transparent WebP image.
+const transparentWebP = "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA=="
+
+func TestDetectContentType(t *testing.T) {
+	webp, err := base64.StdEncoding.DecodeString(transparentWebP)
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"\xFF\xD8\xFF\xE0":         imaging.TypeJPEG,
+		"\x89PNG\r\n\x1a\n":        imaging.TypePNG,
+		"GIF89a":                   imaging.TypeGIF,
+		string(webp):               imaging.TypeWebP,
+		"RIFF\x00\x00\x00\x00WAVE": "",
+		"\x89PNG":                  "",
+	}
+	for data, want := range tests {
+		require.Equal(t, want, imaging.DetectContentType([]byte(data)), "%q", data)
+	}
+}
+
+func TestProcessConvertsGIFAndWebP(t *testing.T) {
+	processor := imaging.NewProcessor(imaging.NewOptions())
+
+	var buf bytes.Buffer
+	paletted := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.White, color.Black})
+	require.NoError(t, gif.EncodeAll(&buf, &gif.GIF{Image: []*image.Paletted{paletted, paletted}, Delay: []int{0, 0}}))
+	out, err := processor.Process(context.Background(), models.Image{ContentType: imaging.TypeGIF, Data: buf.Bytes()})
+	require.NoError(t, err)
+	require.Equal(t, imaging.TypePNG, out.ContentType)
+
+	webp, err := base64.StdEncoding.DecodeString(transparentWebP)
+	require.NoError(t, err)
+	out, err = processor.Process(context.Background(), models.Image{ContentType: imaging.TypeWebP, Data: webp})
+	require.NoError(t, err)
+	// Transparency is kept.
+	require.Equal(t, imaging.TypePNG, out.ContentType)
+
+	out, err = processor.ToJPEG(context.Background(), models.Image{ContentType: imaging.TypeWebP, Data: webp})
+	require.NoError(t, err)
+	require.Equal(t, imaging.TypeJPEG, out.ContentType)
+	img, err := jpeg.Decode(bytes.NewReader(out.Data))
+	require.NoError(t, err)
+	// Flattened onto white.
+	r, g, b, _ := img.At(0, 0).RGBA()
+	require.Greater(t, r+g+b, uint32(3*0xF000))
+}
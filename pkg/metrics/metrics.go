@@ -21,6 +21,7 @@ type Registry struct {
 	counters map[string]*atomic.Int64 // key = fullKey(name, labels)
 	meter    metric.Meter
 	otelCtrs map[string]metric.Int64Counter // base name -> instrument
+	otelGgs  map[string]metric.Int64Gauge   // base name -> instrument
 }
 
 func NewRegistry() *Registry {
@@ -29,6 +30,7 @@ func NewRegistry() *Registry {
 		counters: make(map[string]*atomic.Int64),
 		meter:    m,
 		otelCtrs: make(map[string]metric.Int64Counter),
+		otelGgs:  make(map[string]metric.Int64Gauge),
 	}
 }
 
@@ -99,6 +101,35 @@ func (r *Registry) Inc(ctx context.Context, name string, labels map[string]strin
 	}
 }
 
+// Set stores the current value of a named gauge with labels.
+// Gauges are exposed together with counters and mirrored to an OTel gauge.
+func (r *Registry) Set(ctx context.Context, name string, labels map[string]string, v int64) {
+	key := fullKey(name, labels)
+
+	r.mu.Lock()
+	c := r.counters[key]
+	if c == nil {
+		c = new(atomic.Int64)
+		r.counters[key] = c
+	}
+	inst := r.otelGgs[name]
+	if inst == nil {
+		g, _ := r.meter.Int64Gauge(name)
+		r.otelGgs[name] = g
+		inst = g
+	}
+	r.mu.Unlock()
+	c.Store(v)
+
+	if inst != nil {
+		attrs := make([]attribute.KeyValue, 0, len(labels))
+		for k, v := range labels {
+			attrs = append(attrs, attribute.String(k, v))
+		}
+		inst.Record(ctx, v, metric.WithAttributes(attrs...))
+	}
+}
+
 // Snapshot returns sorted text lines representing current counters.
 func (r *Registry) SnapshotLines() []string {
 	r.mu.RLock()
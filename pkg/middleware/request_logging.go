@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,7 +32,7 @@ func RequestLogger(reg *metrics.Registry) echo.MiddlewareFunc {
 				Str("user_agent", req.UserAgent()).
 				Logger()
 
-			ctx := logger.WithContext(req.Context())
+			ctx := logger.WithContext(context.WithValue(req.Context(), requestIDKey{}, rid))
 			c.SetRequest(req.WithContext(ctx))
 
 			err := next(c)
@@ -75,6 +76,14 @@ func RequestLogger(reg *metrics.Registry) echo.MiddlewareFunc {
 	}
 }
 
+type requestIDKey struct{}
+
+// RequestID returns the X-Request-ID attached by RequestLogger, or "".
+func RequestID(ctx context.Context) string {
+	rid, _ := ctx.Value(requestIDKey{}).(string)
+	return rid
+}
+
 func intToClass(code int) string {
 	switch {
 	case code >= 100 && code < 200:
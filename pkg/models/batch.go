@@ -0,0 +1,35 @@
+package models
+
+// BatchStatus is the upstream processing state of a batch.
+type BatchStatus string
+
+const (
+	BatchCreated    BatchStatus = "created"
+	BatchInProgress BatchStatus = "in_progress"
+	BatchCompleted  BatchStatus = "completed"
+)
+
+// Batch is a set of chat requests processed asynchronously by a provider.
+type Batch struct {
+	// ID is the provider's batch id.
+	ID     string      `json:"id"`
+	Status BatchStatus `json:"status"`
+
+	// Request counters as reported by the provider.
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+
+	// OutputFileID refers to the results; set once the batch is completed.
+	OutputFileID string `json:"output_file_id,omitempty"`
+}
+
+// BatchResult is the outcome of one request of a batch.
+type BatchResult struct {
+	// Index is the position of the request in the submitted batch.
+	Index int `json:"index"`
+
+	// Response is set when the request succeeded, Error otherwise.
+	Response *ChatResponse `json:"response,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
@@ -1,5 +1,42 @@
 package models
 
+import "encoding/base64"
+
+// Chat message roles shared by GigaChat and OpenAI.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// ChatRequest is a backend-agnostic chat completion request.
+// The system prompt is added by the model client and must not be included.
+type ChatRequest struct {
+	// Messages is the conversation history; the last message is the new user turn.
+	Messages []ChatMessage `json:"messages"`
+
+	// SessionID identifies the conversation upstream (enables prompt caching).
+	SessionID string `json:"session_id,omitempty"`
+
+	// Options overrides provider generation defaults.
+	Options GenerationOptions `json:"options,omitempty"`
+}
+
+// GenerationOptions are per-request sampling parameters. Empty fields keep
+// the provider defaults.
+type GenerationOptions struct {
+	Model             string   `json:"model,omitempty"`
+	Temperature       *float64 `json:"temperature,omitempty"`
+	TopP              *float64 `json:"top_p,omitempty"`
+	MaxTokens         *int     `json:"max_tokens,omitempty"`
+	RepetitionPenalty *float64 `json:"repetition_penalty,omitempty"`
+}
+
+// NewTextRequest builds a stateless single-turn request.
+func NewTextRequest(text string) ChatRequest {
+	return ChatRequest{Messages: []ChatMessage{{Role: RoleUser, Content: text}}}
+}
+
 // ChatResponse is a unified chat completion response model compatible
 // with both GigaChat API and OpenAI Chat Completions.
 // Fields are optional when they are not provided by a backend.
@@ -42,6 +79,23 @@ type ChatMessage struct {
 
 	// ToolCalls are supported by OpenAI. Left empty for GigaChat unless mapped.
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// Images are attached to user messages sent to vision models.
+	Images []Image `json:"images,omitempty"`
+}
+
+// Image is a picture attached to a user message. Providers use either
+// the URL (OpenAI downloads it) or the raw bytes (GigaChat uploads them).
+// URL is empty when the image must be delivered inline.
+type Image struct {
+	URL         string `json:"url,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Data        []byte `json:"-"`
+}
+
+// DataURL returns the image bytes as a base64 data: URL.
+func (i Image) DataURL() string {
+	return "data:" + i.ContentType + ";base64," + base64.StdEncoding.EncodeToString(i.Data)
 }
 
 // FunctionCall contains function name and arguments.
@@ -65,3 +119,21 @@ type ChatUsage struct {
 	TotalTokens           int32 `json:"total_tokens,omitempty"`
 	PrecachedPromptTokens int32 `json:"precached_prompt_tokens,omitempty"`
 }
+
+// ChatChunk is a single piece of a streamed chat completion.
+// Usage and FinishReason are set only on the chunks that carry them
+// (usually the last ones in the stream).
+type ChatChunk struct {
+	Model        string     `json:"model,omitempty"`
+	Content      string     `json:"content,omitempty"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        *ChatUsage `json:"usage,omitempty"`
+}
+
+// ChatStream iterates over chunks of a streamed chat completion.
+type ChatStream interface {
+	// Next returns the next chunk or io.EOF when the stream is finished.
+	Next() (ChatChunk, error)
+	// Close releases the underlying connection.
+	Close() error
+}
@@ -0,0 +1,23 @@
+package models_test
+
+import (
+	"testing"
+
+	"pod_api/pkg/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageDataURL(t *testing.T) {
+	tests := []struct {
+		image models.Image
+		want  string
+	}{
+		{models.Image{ContentType: "image/png", Data: []byte("\x89PNG")}, "data:image/png;base64,iVBORw=="},
+		{models.Image{ContentType: "image/jpeg", Data: []byte{0xff, 0xd8, 0xff}}, "data:image/jpeg;base64,/9j/"},
+		{models.Image{ContentType: "image/webp"}, "data:image/webp;base64,"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.image.DataURL())
+	}
+}
@@ -1,5 +1,7 @@
 package prompting
 
+import "strings"
+
 // SystemPrompt returns the shared instruction for vision/text classification
 // to produce a normalized JSON response about fashion items.
 // The model must return ONLY JSON without any extra text.
@@ -80,3 +82,17 @@ func SystemPrompt() string {
 6. Не вставляй текст вне JSON, даже перевод строки.
 `
 }
+
+// RepairPrompt returns a follow-up user message asking the model to fix
+// its previous answer. problems are human-readable contract violations.
+func RepairPrompt(problems []string) string {
+	var b strings.Builder
+	b.WriteString("Твой предыдущий ответ нарушает требуемый формат. Найденные ошибки:\n")
+	for _, problem := range problems {
+		b.WriteString("- ")
+		b.WriteString(problem)
+		b.WriteByte('\n')
+	}
+	b.WriteString("Исправь ошибки и верни полный ответ заново: только JSON-массив по правилам из системного сообщения, без текста вне JSON.")
+	return b.String()
+}
@@ -0,0 +1,230 @@
+package providers
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a provider circuit breaker.
+type BreakerState string
+
+const (
+	// BreakerClosed lets every call through.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen rejects calls until OpenTimeout elapses.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a limited number of probe calls through.
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// BreakerOptions controls when a provider is taken out of rotation.
+type BreakerOptions struct {
+	// FailureThreshold opens the breaker after this many consecutive failures (0 disables).
+	FailureThreshold int
+	// ErrorRate opens the breaker when the share of failed calls in the
+	// window reaches it (0 disables).
+	ErrorRate float64
+	// Window is the number of recent calls used to compute the error rate.
+	Window int
+	// MinCalls is the number of calls in the window required before ErrorRate applies.
+	MinCalls int
+	// OpenTimeout is how long the breaker stays open before probing.
+	OpenTimeout time.Duration
+	// HalfOpenProbes limits concurrent calls while half-open.
+	HalfOpenProbes int
+}
+
+// NewBreakerOptions returns sensible defaults.
+func NewBreakerOptions() BreakerOptions {
+	return BreakerOptions{
+		FailureThreshold: 5,
+		ErrorRate:        0.5,
+		Window:           20,
+		MinCalls:         10,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenProbes:   1,
+	}
+}
+
+// BreakerStatus is a point-in-time view of a breaker.
+type BreakerStatus struct {
+	State               BreakerState
+	ConsecutiveFailures int
+	// ErrorRate is the share of failures among Calls recent calls.
+	ErrorRate float64
+	Calls     int
+	// OpenedAt is set while the breaker is open or half-open.
+	OpenedAt time.Time
+}
+
+// Breaker tracks call outcomes of a single provider.
+type Breaker struct {
+	mu   sync.Mutex
+	opts BreakerOptions
+
+	state       BreakerState
+	consecutive int
+	// outcomes is a ring buffer of recent calls, true for failures.
+	outcomes []bool
+	next     int
+	calls    int
+	failures int
+	openedAt time.Time
+	probes   int
+
+	now func() time.Time
+	// onChange is called outside the lock after every state transition.
+	onChange func(from, to BreakerState)
+}
+
+// NewBreaker creates a closed breaker.
+func NewBreaker(opts BreakerOptions) *Breaker {
+	if opts.Window <= 0 {
+		opts.Window = 1
+	}
+	if opts.HalfOpenProbes <= 0 {
+		opts.HalfOpenProbes = 1
+	}
+	return &Breaker{
+		opts:     opts,
+		state:    BreakerClosed,
+		outcomes: make([]bool, opts.Window),
+		now:      time.Now,
+	}
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by exactly one Success, Failure or Release.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	from := b.state
+	allowed := false
+	switch b.state {
+	case BreakerClosed:
+		allowed = true
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) >= b.opts.OpenTimeout {
+			b.state = BreakerHalfOpen
+			b.probes = 1
+			allowed = true
+		}
+	case BreakerHalfOpen:
+		if b.probes < b.opts.HalfOpenProbes {
+			b.probes++
+			allowed = true
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return allowed
+}
+
+// Success records a successful call.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	from := b.state
+	b.consecutive = 0
+	if b.state == BreakerHalfOpen {
+		// The provider is back: start over with a clean window.
+		b.reset()
+		b.state = BreakerClosed
+	} else {
+		b.record(false)
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// Failure records a failed call and opens the breaker when a threshold is reached.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	from := b.state
+	b.consecutive++
+	switch b.state {
+	case BreakerHalfOpen:
+		b.open()
+	case BreakerClosed:
+		b.record(true)
+		if b.tripped() {
+			b.open()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// Release returns a probe slot without recording an outcome, e.g. when
+// the caller cancelled the request.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+	b.mu.Unlock()
+}
+
+// Status returns the current breaker view.
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.consecutive,
+		Calls:               b.calls,
+	}
+	if b.calls > 0 {
+		status.ErrorRate = float64(b.failures) / float64(b.calls)
+	}
+	if b.state != BreakerClosed {
+		status.OpenedAt = b.openedAt
+	}
+	return status
+}
+
+func (b *Breaker) tripped() bool {
+	if b.opts.FailureThreshold > 0 && b.consecutive >= b.opts.FailureThreshold {
+		return true
+	}
+	if b.opts.ErrorRate > 0 && b.calls >= max(b.opts.MinCalls, 1) {
+		return float64(b.failures)/float64(b.calls) >= b.opts.ErrorRate
+	}
+	return false
+}
+
+func (b *Breaker) record(failed bool) {
+	if b.calls == len(b.outcomes) {
+		if b.outcomes[b.next] {
+			b.failures--
+		}
+	} else {
+		b.calls++
+	}
+	b.outcomes[b.next] = failed
+	if failed {
+		b.failures++
+	}
+	b.next = (b.next + 1) % len(b.outcomes)
+}
+
+func (b *Breaker) open() {
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.probes = 0
+}
+
+func (b *Breaker) reset() {
+	clear(b.outcomes)
+	b.next, b.calls, b.failures, b.probes = 0, 0, 0, 0
+}
+
+func (b *Breaker) notify(from, to BreakerState) {
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
@@ -0,0 +1,288 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/metrics"
+	"pod_api/pkg/models"
+)
+
+var (
+	// ErrNoProvider is returned when every provider in a chain is rejected
+	// by its circuit breaker.
+	ErrNoProvider = errors.New("no healthy provider available")
+
+	// ErrNotSupported is returned by StreamMessage when no provider in the
+	// chain can stream.
+	ErrNotSupported = errors.New("capability is not supported by the providers")
+
+	// ErrModelNotSupported is returned when a request selects a model that
+	// no provider in the chain can serve.
+	ErrModelNotSupported = errors.New("model is not supported by the providers")
+
+	// ErrImageTypeNotSupported is returned when no provider in the chain
+	// accepts the image format and transcoding is disabled.
+	ErrImageTypeNotSupported = errors.New("image type is not supported by the providers")
+)
+
+// member is a chain entry with its breaker.
+type member struct {
+	provider Provider
+	breaker  *Breaker
+}
+
+// Chain routes calls through an ordered list of providers: a failed call
+// or an open breaker moves the request to the next provider.
+// Chain implements TextModel, StreamingTextModel and ImageModel.
+type Chain struct {
+	capability Capability
+	members    []member
+	transcode  Transcoder
+	reg        *metrics.Registry
+}
+
+// Providers returns provider names in fallback order.
+func (c *Chain) Providers() []string {
+	names := make([]string, 0, len(c.members))
+	for _, m := range c.members {
+		names = append(names, m.provider.Name())
+	}
+	return names
+}
+
+// SendMessage implements TextModel.
+func (c *Chain) SendMessage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	return c.call(ctx, request, func(p Provider, request models.ChatRequest) (*models.ChatResponse, error) {
+		return p.(TextModel).SendMessage(ctx, request)
+	})
+}
+
+// SendImage implements ImageModel.
+func (c *Chain) SendImage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	return c.call(ctx, request, func(p Provider, request models.ChatRequest) (*models.ChatResponse, error) {
+		return p.(ImageModel).SendImage(ctx, request)
+	})
+}
+
+// StreamMessage implements StreamingTextModel. Only providers with
+// CapabilityStreaming take part; once the first chunk has been requested
+// the stream is not switched to another provider.
+func (c *Chain) StreamMessage(ctx context.Context, request models.ChatRequest) (models.ChatStream, error) {
+	var lastErr error
+	tried, served := false, false
+	for i, m := range c.members {
+		if !m.provider.Capabilities().Has(CapabilityStreaming) {
+			continue
+		}
+		tried = true
+		if !servesModel(m.provider, request) {
+			continue
+		}
+		served = true
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !m.breaker.Allow() {
+			c.count(m, "rejected")
+			continue
+		}
+		stream, err := m.provider.(StreamingTextModel).StreamMessage(ctx, request)
+		if err != nil {
+			c.finish(ctx, m, err)
+			lastErr = err
+			c.fallback(i, err)
+			continue
+		}
+		return &chainStream{ChatStream: stream, ctx: ctx, chain: c, member: m}, nil
+	}
+	if !tried {
+		return nil, ErrNotSupported
+	}
+	if !served {
+		return nil, ErrModelNotSupported
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, ErrNoProvider
+}
+
+// call sends the request to the first healthy provider that can serve it;
+// send receives the request adapted to the provider's image formats.
+func (c *Chain) call(ctx context.Context, request models.ChatRequest, send func(p Provider, request models.ChatRequest) (*models.ChatResponse, error)) (*models.ChatResponse, error) {
+	var lastErr error
+	served, imagesRejected := false, false
+	for i, m := range c.members {
+		if !servesModel(m.provider, request) {
+			continue
+		}
+		if c.transcode == nil && !acceptsImages(m.provider, request) {
+			imagesRejected = true
+			continue
+		}
+		served = true
+		// The caller is gone or out of time: no point in asking the next provider.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !m.breaker.Allow() {
+			c.count(m, "rejected")
+			continue
+		}
+		adapted, err := c.adaptImages(ctx, m.provider, request)
+		if err != nil {
+			// The image itself is broken, another provider would not help.
+			m.breaker.Release()
+			return nil, err
+		}
+		response, err := send(m.provider, adapted)
+		c.finish(ctx, m, err)
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+		c.fallback(i, err)
+	}
+	if !served {
+		if imagesRejected {
+			return nil, ErrImageTypeNotSupported
+		}
+		return nil, ErrModelNotSupported
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, ErrNoProvider
+}
+
+// adaptImages transcodes the images the provider does not accept. Only
+// the transcoded images are copied; links to them are dropped, so they go
+// inline.
+func (c *Chain) adaptImages(ctx context.Context, p Provider, request models.ChatRequest) (models.ChatRequest, error) {
+	if c.transcode == nil || acceptsImages(p, request) {
+		return request, nil
+	}
+	selector := p.(ImageTypeSelector)
+	messages := make([]models.ChatMessage, len(request.Messages))
+	for i, message := range request.Messages {
+		if len(message.Images) != 0 {
+			images := make([]models.Image, len(message.Images))
+			for j, image := range message.Images {
+				if !selector.SupportsImageType(image.ContentType) {
+					converted, err := c.transcode(ctx, image)
+					if err != nil {
+						return request, err
+					}
+					image = converted
+				}
+				images[j] = image
+			}
+			message.Images = images
+		}
+		messages[i] = message
+	}
+	request.Messages = messages
+	return request, nil
+}
+
+// acceptsImages reports whether the provider accepts every image of the
+// request. Providers without ImageTypeSelector accept any image.
+func acceptsImages(p Provider, request models.ChatRequest) bool {
+	selector, ok := p.(ImageTypeSelector)
+	if !ok {
+		return true
+	}
+	for _, message := range request.Messages {
+		for _, image := range message.Images {
+			if !selector.SupportsImageType(image.ContentType) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// servesModel reports whether the provider can serve the requested model.
+// Providers without ModelSelector ignore the model option.
+func servesModel(p Provider, request models.ChatRequest) bool {
+	if request.Options.Model == "" {
+		return true
+	}
+	selector, ok := p.(ModelSelector)
+	return !ok || selector.SupportsModel(request.Options.Model)
+}
+
+// finish records the call outcome. Calls that failed because the caller
+// cancelled or ran out of time say nothing about the provider health and
+// only release the breaker slot.
+func (c *Chain) finish(ctx context.Context, m member, err error) {
+	switch {
+	case err == nil:
+		m.breaker.Success()
+		c.count(m, "success")
+	case ctx.Err() != nil || errors.Is(err, context.Canceled):
+		m.breaker.Release()
+		c.count(m, "cancelled")
+	default:
+		m.breaker.Failure()
+		c.count(m, "failure")
+	}
+}
+
+// fallback logs a failed call; the request moves on to the next provider, if any.
+func (c *Chain) fallback(failed int, err error) {
+	from := c.members[failed].provider.Name()
+	last := failed == len(c.members)-1
+	log.Warn().Err(err).
+		Str("capability", string(c.capability)).
+		Str("provider", from).
+		Bool("last", last).
+		Msg("provider call failed")
+	if c.reg != nil && !last {
+		c.reg.Inc(context.Background(), "provider_fallbacks_total", map[string]string{"capability": string(c.capability), "from": from}, 1)
+	}
+}
+
+func (c *Chain) count(m member, outcome string) {
+	if c.reg != nil {
+		c.reg.Inc(context.Background(), "provider_calls_total", map[string]string{
+			"provider":   m.provider.Name(),
+			"capability": string(c.capability),
+			"outcome":    outcome,
+		}, 1)
+	}
+}
+
+// chainStream reports the stream outcome to the provider breaker.
+type chainStream struct {
+	models.ChatStream
+	ctx      context.Context
+	chain    *Chain
+	member   member
+	finished bool
+}
+
+func (s *chainStream) Next() (models.ChatChunk, error) {
+	chunk, err := s.ChatStream.Next()
+	if err != nil && !s.finished {
+		s.finished = true
+		outcome := err
+		if errors.Is(err, io.EOF) {
+			outcome = nil
+		}
+		s.chain.finish(s.ctx, s.member, outcome)
+	}
+	return chunk, err
+}
+
+func (s *chainStream) Close() error {
+	if !s.finished {
+		// Abandoned by the caller before the end.
+		s.finished = true
+		s.chain.finish(s.ctx, s.member, context.Canceled)
+	}
+	return s.ChatStream.Close()
+}
@@ -0,0 +1,171 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pod_api/pkg/clients/fake"
+	"pod_api/pkg/models"
+	"pod_api/pkg/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// flaky is a text provider whose calls fail while down is set.
+type flaky struct {
+	down  bool
+	calls int
+}
+
+func (f *flaky) Name() string { return "flaky" }
+func (f *flaky) Capabilities() providers.Capabilities {
+	return providers.Capabilities{providers.CapabilityText}
+}
+func (f *flaky) SendMessage(context.Context, models.ChatRequest) (*models.ChatResponse, error) {
+	f.calls++
+	if f.down {
+		return nil, errors.New("upstream unavailable")
+	}
+	return &models.ChatResponse{Model: "flaky"}, nil
+}
+
+func TestChainFallbackAndBreaker(t *testing.T) {
+	opts := providers.NewOptions()
+	opts.Breaker.FailureThreshold = 2
+	opts.Breaker.ErrorRate = 0
+	opts.Breaker.OpenTimeout = 20 * time.Millisecond
+	registry := providers.NewRegistry(opts)
+
+	primary := &flaky{down: true}
+	require.NoError(t, registry.Register(primary))
+	require.NoError(t, registry.Register(fake.NewClient()))
+
+	chain, err := registry.Text("flaky", fake.ProviderName)
+	require.NoError(t, err)
+	require.Equal(t, []string{"flaky", fake.ProviderName}, chain.Providers())
+
+	// Failures are re-routed to the next provider until the breaker opens.
+	for range 2 {
+		response, err := chain.SendMessage(context.Background(), models.NewTextRequest("образ"))
+		require.NoError(t, err)
+		require.Equal(t, fake.ModelName, response.Model)
+	}
+	require.Equal(t, providers.BreakerOpen, breakerState(registry, "flaky"))
+
+	// An open breaker skips the provider without calling it.
+	_, err = chain.SendMessage(context.Background(), models.NewTextRequest("образ"))
+	require.NoError(t, err)
+	require.Equal(t, 2, primary.calls)
+
+	// After the timeout a successful probe closes the breaker.
+	primary.down = false
+	time.Sleep(30 * time.Millisecond)
+	response, err := chain.SendMessage(context.Background(), models.NewTextRequest("образ"))
+	require.NoError(t, err)
+	require.Equal(t, "flaky", response.Model)
+	require.Equal(t, providers.BreakerClosed, breakerState(registry, "flaky"))
+}
+
+func TestChainStopsOnCancelledContext(t *testing.T) {
+	registry := providers.NewRegistry(providers.NewOptions())
+	primary := &flaky{}
+	require.NoError(t, registry.Register(primary))
+	chain, err := registry.Text("flaky")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = chain.SendMessage(ctx, models.NewTextRequest("образ"))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, primary.calls)
+	require.Equal(t, providers.BreakerClosed, breakerState(registry, "flaky"))
+}
+
+func TestBreakerErrorRate(t *testing.T) {
+	opts := providers.NewBreakerOptions()
+	opts.FailureThreshold = 0
+	opts.ErrorRate = 0.5
+	opts.Window = 4
+	opts.MinCalls = 4
+	breaker := providers.NewBreaker(opts)
+
+	for _, failed := range []bool{true, false, true} {
+		require.True(t, breaker.Allow())
+		if failed {
+			breaker.Failure()
+		} else {
+			breaker.Success()
+		}
+	}
+	// Not enough calls yet to apply the rate.
+	require.Equal(t, providers.BreakerClosed, breaker.Status().State)
+
+	require.True(t, breaker.Allow())
+	breaker.Success()
+	require.True(t, breaker.Allow())
+	breaker.Failure()
+
+	status := breaker.Status()
+	require.Equal(t, providers.BreakerOpen, status.State)
+	require.InDelta(t, 0.5, status.ErrorRate, 1e-9)
+	require.False(t, breaker.Allow())
+}
+
+func breakerState(registry *providers.Registry, name string) providers.BreakerState {
+	for _, status := range registry.Status() {
+		if status.Name == name {
+			return status.Breaker.State
+		}
+	}
+	return ""
+}
+
+// pngOnly is a vision provider that accepts PNG images only.
+type pngOnly struct {
+	received []string
+}
+
+func (p *pngOnly) Name() string { return "png-only" }
+func (p *pngOnly) Capabilities() providers.Capabilities {
+	return providers.Capabilities{providers.CapabilityText, providers.CapabilityVision}
+}
+func (p *pngOnly) SupportsImageType(contentType string) bool { return contentType == "image/png" }
+func (p *pngOnly) SendMessage(context.Context, models.ChatRequest) (*models.ChatResponse, error) {
+	return &models.ChatResponse{}, nil
+}
+func (p *pngOnly) SendImage(_ context.Context, request models.ChatRequest) (*models.ChatResponse, error) {
+	for _, image := range request.Messages[0].Images {
+		p.received = append(p.received, image.ContentType)
+	}
+	return &models.ChatResponse{}, nil
+}
+
+func TestChainImageTypes(t *testing.T) {
+	request := models.NewTextRequest("образ")
+	request.Messages[0].Images = []models.Image{{ContentType: "image/png"}, {ContentType: "image/webp", URL: "/api/v1/images/1"}}
+
+	// Without a transcoder the provider is skipped.
+	registry := providers.NewRegistry(providers.NewOptions())
+	require.NoError(t, registry.Register(&pngOnly{}))
+	chain, err := registry.Image("png-only")
+	require.NoError(t, err)
+	_, err = chain.SendImage(context.Background(), request)
+	require.ErrorIs(t, err, providers.ErrImageTypeNotSupported)
+
+	// With a transcoder only the unsupported image is converted.
+	opts := providers.NewOptions()
+	opts.Transcode = func(_ context.Context, image models.Image) (models.Image, error) {
+		return models.Image{ContentType: "image/png", Data: []byte("converted")}, nil
+	}
+	registry = providers.NewRegistry(opts)
+	provider := &pngOnly{}
+	require.NoError(t, registry.Register(provider))
+	chain, err = registry.Image("png-only")
+	require.NoError(t, err)
+	_, err = chain.SendImage(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, []string{"image/png", "image/png"}, provider.received)
+	require.Equal(t, "image/webp", request.Messages[0].Images[1].ContentType, "caller request is not modified")
+}
@@ -0,0 +1,91 @@
+// Package providers describes model backends and their capabilities.
+// Backends (pkg/clients/*) are registered in a Registry and selected by config.
+package providers
+
+import (
+	"context"
+	"slices"
+
+	"pod_api/pkg/models"
+)
+
+// Capability is a feature a provider can serve.
+type Capability string
+
+const (
+	CapabilityText       Capability = "text"
+	CapabilityVision     Capability = "vision"
+	CapabilityStreaming  Capability = "streaming"
+	CapabilityFunctions  Capability = "functions"
+	CapabilityEmbeddings Capability = "embeddings"
+	CapabilityBatch      Capability = "batch"
+)
+
+// Capabilities is a set of provider features.
+type Capabilities []Capability
+
+// Has reports whether the set contains c.
+func (c Capabilities) Has(capability Capability) bool {
+	return slices.Contains(c, capability)
+}
+
+// Provider is a model backend.
+type Provider interface {
+	// Name is the identifier used in configuration, e.g. "gigachat".
+	Name() string
+	// Capabilities lists features the backend supports.
+	Capabilities() Capabilities
+}
+
+// ModelSelector is implemented by providers that can serve a model other
+// than their default one (see models.GenerationOptions.Model).
+type ModelSelector interface {
+	// SupportsModel reports whether the provider can serve the model.
+	SupportsModel(model string) bool
+}
+
+// ImageTypeSelector is implemented by vision providers that accept only
+// some image formats. Providers without it accept any supported image.
+type ImageTypeSelector interface {
+	// SupportsImageType reports whether the provider accepts the content type.
+	SupportsImageType(contentType string) bool
+}
+
+// Transcoder converts an image into a format every vision provider
+// accepts (see imaging.Processor.ToJPEG).
+type Transcoder func(ctx context.Context, image models.Image) (models.Image, error)
+
+// TextModel is implemented by providers with CapabilityText.
+type TextModel interface {
+	// SendMessage sends user text to the model and returns a unified
+	// chat response compatible with GigaChat/OpenAI along with an error.
+	// Cancelling ctx aborts the upstream request.
+	SendMessage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error)
+}
+
+// StreamingTextModel is implemented by providers with CapabilityStreaming.
+type StreamingTextModel interface {
+	// StreamMessage sends user text to the model and returns a stream of
+	// completion chunks. Cancelling ctx aborts the upstream request.
+	StreamMessage(ctx context.Context, request models.ChatRequest) (models.ChatStream, error)
+}
+
+// ImageModel is implemented by providers with CapabilityVision.
+type ImageModel interface {
+	// SendImage sends a conversation whose user messages carry images
+	// to a vision model and returns a unified chat response.
+	// Cancelling ctx aborts the upstream request.
+	SendImage(ctx context.Context, request models.ChatRequest) (*models.ChatResponse, error)
+}
+
+// BatchModel is implemented by providers with CapabilityBatch. Batches are
+// neither retried nor routed through the breaker: a submitted batch lives
+// upstream until it completes.
+type BatchModel interface {
+	// CreateBatch submits text requests for asynchronous processing.
+	CreateBatch(ctx context.Context, requests []models.ChatRequest) (models.Batch, error)
+	// GetBatch returns the current state of a submitted batch.
+	GetBatch(ctx context.Context, id string) (models.Batch, error)
+	// BatchResults downloads the answers of a completed batch.
+	BatchResults(ctx context.Context, batch models.Batch) ([]models.BatchResult, error)
+}
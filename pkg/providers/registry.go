@@ -0,0 +1,211 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/metrics"
+)
+
+// Registry keeps configured providers by name, each with its own circuit breaker.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+	breakers  map[string]*Breaker
+	opts      Options
+}
+
+// Options controls optional parameters for NewRegistry.
+type Options struct {
+	Breaker BreakerOptions
+
+	// Transcode is optional; it converts images a provider does not accept
+	// (ImageTypeSelector). With nil such providers are skipped.
+	Transcode Transcoder
+
+	// Metrics is optional; nil disables provider metrics.
+	Metrics *metrics.Registry
+}
+
+// NewOptions returns sensible defaults.
+func NewOptions() Options {
+	return Options{Breaker: NewBreakerOptions()}
+}
+
+// ProviderStatus describes a registered provider and its breaker.
+type ProviderStatus struct {
+	Name         string
+	Capabilities Capabilities
+	Breaker      BreakerStatus
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry(opts Options) *Registry {
+	return &Registry{
+		providers: make(map[string]Provider),
+		breakers:  make(map[string]*Breaker),
+		opts:      opts,
+	}
+}
+
+// Register adds a provider. It fails on duplicate names and when a declared
+// capability is not backed by the corresponding model interface.
+func (r *Registry) Register(p Provider) error {
+	if p == nil {
+		return fmt.Errorf("provider should not be nil")
+	}
+	if err := checkCapabilities(p); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.providers[p.Name()]; ok {
+		return fmt.Errorf("provider %q is already registered", p.Name())
+	}
+	r.providers[p.Name()] = p
+	r.breakers[p.Name()] = r.newBreaker(p.Name())
+	return nil
+}
+
+// Get returns a provider by name.
+func (r *Registry) Get(name string) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns registered provider names in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Text returns a fallback chain over the named text providers.
+func (r *Registry) Text(names ...string) (*Chain, error) {
+	return r.chain(CapabilityText, names)
+}
+
+// Image returns a fallback chain over the named vision providers.
+func (r *Registry) Image(names ...string) (*Chain, error) {
+	return r.chain(CapabilityVision, names)
+}
+
+// Batch returns the first of the named providers that processes batches.
+func (r *Registry) Batch(names ...string) (BatchModel, error) {
+	for _, name := range names {
+		if p, err := r.lookup(name, CapabilityBatch); err == nil {
+			return p.(BatchModel), nil
+		}
+	}
+	return nil, fmt.Errorf("no providers configured for %s", CapabilityBatch)
+}
+
+// Status returns registered providers with breaker state, sorted by name.
+func (r *Registry) Status() []ProviderStatus {
+	names := r.Names()
+	statuses := make([]ProviderStatus, 0, len(names))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, name := range names {
+		statuses = append(statuses, ProviderStatus{
+			Name:         name,
+			Capabilities: r.providers[name].Capabilities(),
+			Breaker:      r.breakers[name].Status(),
+		})
+	}
+	return statuses
+}
+
+func (r *Registry) chain(capability Capability, names []string) (*Chain, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no providers configured for %s", capability)
+	}
+	chain := &Chain{capability: capability, transcode: r.opts.Transcode, reg: r.opts.Metrics}
+	for _, name := range names {
+		p, err := r.lookup(name, capability)
+		if err != nil {
+			return nil, err
+		}
+		r.mu.RLock()
+		breaker := r.breakers[name]
+		r.mu.RUnlock()
+		chain.members = append(chain.members, member{provider: p, breaker: breaker})
+	}
+	return chain, nil
+}
+
+// newBreaker creates a provider breaker that logs and reports its state.
+func (r *Registry) newBreaker(name string) *Breaker {
+	breaker := NewBreaker(r.opts.Breaker)
+	reg := r.opts.Metrics
+	labels := map[string]string{"provider": name}
+	if reg != nil {
+		reg.Set(context.Background(), "provider_breaker_state", labels, breakerStateValue(BreakerClosed))
+	}
+	breaker.onChange = func(from, to BreakerState) {
+		log.Warn().Str("provider", name).Str("from", string(from)).Str("to", string(to)).Msg("provider breaker state changed")
+		if reg != nil {
+			reg.Set(context.Background(), "provider_breaker_state", labels, breakerStateValue(to))
+			reg.Inc(context.Background(), "provider_breaker_transitions_total", map[string]string{"provider": name, "state": string(to)}, 1)
+		}
+	}
+	return breaker
+}
+
+// breakerStateValue encodes the state for the provider_breaker_state gauge.
+func breakerStateValue(state BreakerState) int64 {
+	switch state {
+	case BreakerOpen:
+		return 2
+	case BreakerHalfOpen:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (r *Registry) lookup(name string, capability Capability) (Provider, error) {
+	p, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+	if !p.Capabilities().Has(capability) {
+		return nil, fmt.Errorf("provider %q does not support %s", name, capability)
+	}
+	return p, nil
+}
+
+// checkCapabilities verifies that declared capabilities have implementations.
+func checkCapabilities(p Provider) error {
+	for _, capability := range p.Capabilities() {
+		var ok bool
+		switch capability {
+		case CapabilityText:
+			_, ok = p.(TextModel)
+		case CapabilityVision:
+			_, ok = p.(ImageModel)
+		case CapabilityStreaming:
+			_, ok = p.(StreamingTextModel)
+		case CapabilityBatch:
+			_, ok = p.(BatchModel)
+		default:
+			// Declarative only: no model interface yet
+			ok = true
+		}
+		if !ok {
+			return fmt.Errorf("provider %q declares %s but does not implement it", p.Name(), capability)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package providers_test
+
+import (
+	"context"
+	"testing"
+
+	"pod_api/pkg/clients/fake"
+	"pod_api/pkg/fashion"
+	"pod_api/pkg/models"
+	"pod_api/pkg/providers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// textOnly declares vision without implementing ImageModel.
+type textOnly struct{}
+
+func (textOnly) Name() string { return "broken" }
+func (textOnly) Capabilities() providers.Capabilities {
+	return providers.Capabilities{providers.CapabilityText, providers.CapabilityVision}
+}
+func (textOnly) SendMessage(context.Context, models.ChatRequest) (*models.ChatResponse, error) {
+	return nil, nil
+}
+
+func TestRegistry(t *testing.T) {
+	registry := providers.NewRegistry(providers.NewOptions())
+	require.NoError(t, registry.Register(fake.NewClient()))
+	require.Error(t, registry.Register(fake.NewClient()), "duplicate name")
+	require.Error(t, registry.Register(textOnly{}), "undeclared implementation")
+	require.Equal(t, []string{fake.ProviderName}, registry.Names())
+
+	text, err := registry.Text(fake.ProviderName)
+	require.NoError(t, err)
+	response, err := text.SendMessage(context.Background(), models.NewTextRequest("осенний образ"))
+	require.NoError(t, err)
+	require.True(t, fashion.Parse(response.Choices[0].Message.Content).Valid())
+
+	_, err = registry.Image(fake.ProviderName)
+	require.NoError(t, err)
+
+	_, err = registry.Text("missing")
+	require.Error(t, err)
+	_, err = registry.Batch(fake.ProviderName, "missing")
+	require.Error(t, err, "fake does not process batches")
+}
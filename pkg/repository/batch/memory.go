@@ -0,0 +1,111 @@
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/metrics"
+)
+
+type batchEntry struct {
+	batch Batch
+	ttl   time.Duration
+	timer *time.Timer
+}
+
+// MemoryRepository is an in-memory BatchRepository implementation.
+type MemoryRepository struct {
+	mu   sync.RWMutex
+	data map[string]*batchEntry
+	reg  *metrics.Registry
+	now  func() time.Time
+}
+
+// NewMemoryRepository creates an empty in-memory repository.
+func NewMemoryRepository(reg *metrics.Registry) *MemoryRepository {
+	return &MemoryRepository{
+		data: make(map[string]*batchEntry),
+		reg:  reg,
+		now:  time.Now,
+	}
+}
+
+// Create stores a new batch with TTL-based auto-deletion.
+func (r *MemoryRepository) Create(ctx context.Context, batch Batch, ttl time.Duration) (Batch, error) {
+	now := r.now().UTC()
+	batch.ID = uuid.NewString()
+	batch.CreatedAt, batch.UpdatedAt = now, now
+	batch.ExpiresAt = time.Time{}
+	if ttl > 0 {
+		batch.ExpiresAt = now.Add(ttl)
+	}
+
+	entry := &batchEntry{batch: batch, ttl: ttl}
+	r.mu.Lock()
+	if ttl > 0 {
+		id := batch.ID
+		entry.timer = time.AfterFunc(ttl, func() {
+			r.expire(id)
+		})
+	}
+	r.data[batch.ID] = entry
+	r.mu.Unlock()
+
+	log.Ctx(ctx).Info().Str("batch_id", batch.ID).Msg("batch created")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "batches_created_total", map[string]string{}, 1)
+	}
+	return batch, nil
+}
+
+// Get returns the stored batch.
+func (r *MemoryRepository) Get(ctx context.Context, id string) (Batch, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.data[id]
+	if !ok {
+		return Batch{}, false
+	}
+	return e.batch, true
+}
+
+// Update replaces the batch and resets its TTL timer.
+func (r *MemoryRepository) Update(ctx context.Context, batch Batch) (Batch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.data[batch.ID]
+	if !ok {
+		return Batch{}, ErrNotFound
+	}
+	now := r.now().UTC()
+	batch.CreatedAt = e.batch.CreatedAt
+	batch.UpdatedAt = now
+	batch.ExpiresAt = time.Time{}
+	if e.timer != nil {
+		e.timer.Reset(e.ttl)
+		batch.ExpiresAt = now.Add(e.ttl)
+	}
+	e.batch = batch
+	return batch, nil
+}
+
+// expire removes a batch whose TTL has passed.
+func (r *MemoryRepository) expire(id string) {
+	r.mu.Lock()
+	e, ok := r.data[id]
+	if ok {
+		delete(r.data, id)
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	log.Info().Str("batch_id", id).Str("status", string(e.batch.Status)).Msg("batch expired")
+	if r.reg != nil {
+		r.reg.Inc(context.Background(), "batches_expired_total", map[string]string{}, 1)
+	}
+}
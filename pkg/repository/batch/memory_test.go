@@ -0,0 +1,48 @@
+package batch_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pod_api/pkg/repository/batch"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := batch.NewMemoryRepository(nil)
+
+	created, err := repo.Create(ctx, batch.Batch{ProviderID: "upstream", Status: batch.StatusCreated, Items: []batch.Item{{Prompt: "пальто"}}}, time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, created.ID)
+	require.False(t, created.Done())
+
+	created.Status = batch.StatusCompleted
+	created.Items = []batch.Item{{Prompt: "пальто", Content: "[]"}}
+	_, err = repo.Update(ctx, created)
+	require.NoError(t, err)
+
+	stored, ok := repo.Get(ctx, created.ID)
+	require.True(t, ok)
+	require.True(t, stored.Done())
+	require.Equal(t, "upstream", stored.ProviderID)
+	require.Equal(t, "[]", stored.Items[0].Content)
+
+	_, err = repo.Update(ctx, batch.Batch{ID: "missing"})
+	require.ErrorIs(t, err, batch.ErrNotFound)
+}
+
+func TestMemoryRepositoryTTL(t *testing.T) {
+	ctx := context.Background()
+	repo := batch.NewMemoryRepository(nil)
+
+	created, err := repo.Create(ctx, batch.Batch{Status: batch.StatusCreated}, 20*time.Millisecond)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, ok := repo.Get(ctx, created.ID)
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+}
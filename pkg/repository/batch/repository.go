@@ -0,0 +1,64 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a batch does not exist or has expired.
+var ErrNotFound = errors.New("batch not found")
+
+// Status is the state of a batch.
+type Status string
+
+const (
+	StatusCreated    Status = "created"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+)
+
+// Item is a prompt of a batch with its outcome.
+type Item struct {
+	Prompt string
+	// Content is the raw model answer; Error is set instead when the
+	// request failed.
+	Content string
+	Error   string
+}
+
+// Batch is the stored state of a batch submitted to a provider.
+type Batch struct {
+	ID string
+	// ProviderID is the id of the batch at the provider.
+	ProviderID string
+	Status     Status
+	Items      []Item
+	// Request counters reported by the provider while the batch runs.
+	Completed int
+	Failed    int
+	// Error explains why a failed batch has no results.
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// ExpiresAt is when the batch is removed unless updated again.
+	ExpiresAt time.Time
+}
+
+// Done reports whether the batch has finished.
+func (b Batch) Done() bool {
+	return b.Status == StatusCompleted || b.Status == StatusFailed
+}
+
+// BatchRepository stores batches. Batches expire after TTL without updates.
+type BatchRepository interface {
+	// Create stores a new batch and returns it with the UUID identifier and
+	// timestamps filled in. ttl defines how long the batch lives without updates.
+	Create(ctx context.Context, batch Batch, ttl time.Duration) (Batch, error)
+	// Get returns the batch. The boolean indicates presence.
+	Get(ctx context.Context, id string) (Batch, bool)
+	// Update replaces the stored batch and prolongs its TTL; ErrNotFound is
+	// returned for expired batches.
+	Update(ctx context.Context, batch Batch) (Batch, error)
+}
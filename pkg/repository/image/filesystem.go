@@ -0,0 +1,440 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/metrics"
+)
+
+// Layout of the storage directory.
+const (
+	blobsDir = "blobs"
+	metaDir  = "meta"
+	metaExt  = ".json"
+	// tmpPrefix marks files being written; leftovers of a crash are swept.
+	tmpPrefix = ".tmp-"
+)
+
+// orphanGrace is how long a blob without metadata is left alone: Save
+// writes the blob before its sidecar.
+const orphanGrace = time.Minute
+
+// FilesystemOptions controls optional parameters for NewFilesystemRepository.
+type FilesystemOptions struct {
+	// SweepInterval is how often expired files are removed (0 disables the
+	// background sweeper; expired images are still never returned).
+	SweepInterval time.Duration
+
+	// Metrics is optional; nil disables repository metrics.
+	Metrics *metrics.Registry
+}
+
+// NewFilesystemOptions returns sensible defaults.
+func NewFilesystemOptions() FilesystemOptions {
+	return FilesystemOptions{SweepInterval: time.Minute}
+}
+
+// FilesystemRepository is an ImageRepository that keeps images as files in
+// a directory. Blobs are written to a temporary file and renamed into
+// place, so readers never see partial data. The metadata of every image
+// lives in its own JSON sidecar, rewritten atomically when the image
+// changes, so an operation touches only its image's files. The sidecars
+// are loaded into memory on start and survive restarts.
+type FilesystemRepository struct {
+	dir string
+	// mu guards index; the metadata of an entry is guarded by its own lock.
+	mu     sync.Mutex
+	index  map[string]*fsEntry
+	reg    *metrics.Registry
+	now    func() time.Time
+	stopCh chan struct{}
+	// onRemove is optional, see OnRemove; guarded by mu.
+	onRemove RemoveFunc
+}
+
+// fsEntry is an image in the index. mu serialises read counting and
+// sidecar writes of the image.
+type fsEntry struct {
+	mu      sync.Mutex
+	meta    Metadata
+	removed bool
+}
+
+// NewFilesystemRepository opens (or creates) the storage directory, loads
+// the metadata, sweeps expired and orphaned files and starts the
+// background sweeper.
+func NewFilesystemRepository(dir string, opts FilesystemOptions) (*FilesystemRepository, error) {
+	if dir == "" {
+		return nil, errors.New("image storage directory should not be empty")
+	}
+	for _, sub := range []string{blobsDir, metaDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			return nil, fmt.Errorf("create image storage: %w", err)
+		}
+	}
+
+	r := &FilesystemRepository{
+		dir:    dir,
+		index:  map[string]*fsEntry{},
+		reg:    opts.Metrics,
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+	}
+	if err := r.loadMetadata(); err != nil {
+		return nil, err
+	}
+	if err := r.sweep(context.Background()); err != nil {
+		return nil, err
+	}
+	if opts.SweepInterval > 0 {
+		go r.sweeper(opts.SweepInterval)
+	}
+	return r, nil
+}
+
+// Save writes image bytes under a new UUID with TTL-based expiry.
+func (r *FilesystemRepository) Save(ctx context.Context, b []byte, meta Metadata, ttl time.Duration) (string, error) {
+	if len(b) == 0 {
+		return "", errors.New("empty image data")
+	}
+
+	id := uuid.NewString()
+	if err := r.writeAtomic(filepath.Join(r.dir, blobsDir, id), b); err != nil {
+		return "", err
+	}
+
+	meta = describe(b, meta, ttl, r.now())
+	if err := r.writeMeta(id, meta); err != nil {
+		_ = os.Remove(r.blobPath(id))
+		return "", err
+	}
+	r.mu.Lock()
+	r.index[id] = &fsEntry{meta: meta}
+	r.mu.Unlock()
+
+	log.Ctx(ctx).Info().Str("image_id", id).Int("bytes", len(b)).Msg("image saved to disk")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "images_saved_total", map[string]string{}, 1)
+		r.reg.Inc(ctx, "images_bytes_stored_total", map[string]string{}, int64(len(b)))
+	}
+	return id, nil
+}
+
+// Get reads the image by id. Expired images are reported as missing.
+func (r *FilesystemRepository) Get(ctx context.Context, id string) ([]byte, bool) {
+	data, _, ok := r.GetWithMeta(ctx, id)
+	return data, ok
+}
+
+// GetWithMeta reads the image and its metadata by id.
+func (r *FilesystemRepository) GetWithMeta(ctx context.Context, id string) ([]byte, Metadata, bool) {
+	meta, ok := r.Stat(ctx, id)
+	if !ok {
+		return nil, Metadata{}, false
+	}
+
+	data, err := os.ReadFile(r.blobPath(id))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image read failed")
+		}
+		return nil, Metadata{}, false
+	}
+	return data, meta, true
+}
+
+// Stat returns the metadata from the index. Expired images are reported as
+// missing.
+func (r *FilesystemRepository) Stat(_ context.Context, id string) (Metadata, bool) {
+	entry := r.entry(id)
+	if entry == nil {
+		return Metadata{}, false
+	}
+	entry.mu.Lock()
+	meta, removed := entry.meta, entry.removed
+	entry.mu.Unlock()
+	if removed || meta.Expired(r.now()) {
+		return Metadata{}, false
+	}
+	return meta, true
+}
+
+// Delete removes the image file and its sidecar.
+func (r *FilesystemRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	entry, ok := r.index[id]
+	delete(r.index, id)
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	entry.mu.Lock()
+	entry.removed = true
+	size := entry.meta.Size
+	entry.mu.Unlock()
+
+	return r.remove(ctx, id, size)
+}
+
+// Read opens the image file and counts the read in its sidecar; the last
+// allowed read removes the image. The open file stays readable after the
+// removal.
+func (r *FilesystemRepository) Read(ctx context.Context, id string) (io.ReadSeekCloser, Metadata, bool) {
+	entry := r.entry(id)
+	if entry == nil {
+		return nil, Metadata{}, false
+	}
+	f, err := os.Open(r.blobPath(id))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image read failed")
+		}
+		return nil, Metadata{}, false
+	}
+
+	entry.mu.Lock()
+	if entry.removed || entry.meta.Expired(r.now()) {
+		// Deleted or exhausted by a concurrent reader.
+		entry.mu.Unlock()
+		f.Close()
+		return nil, Metadata{}, false
+	}
+	entry.meta.Reads++
+	meta := entry.meta
+	exhausted := meta.MaxReads > 0 && meta.Reads >= meta.MaxReads
+	if exhausted {
+		entry.removed = true
+	} else if err := r.writeMeta(id, meta); err != nil {
+		// The in-memory entry is authoritative; the count is persisted
+		// with the next read.
+		log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image metadata update failed")
+	}
+	entry.mu.Unlock()
+
+	if exhausted {
+		r.mu.Lock()
+		delete(r.index, id)
+		r.mu.Unlock()
+		if err := r.remove(ctx, id, meta.Size); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image file removal failed")
+		}
+	}
+	return f, meta, true
+}
+
+// Open opens the image file without counting a read.
+func (r *FilesystemRepository) Open(ctx context.Context, id string) (io.ReadSeekCloser, Metadata, bool) {
+	meta, ok := r.Stat(ctx, id)
+	if !ok {
+		return nil, Metadata{}, false
+	}
+	f, err := os.Open(r.blobPath(id))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image read failed")
+		}
+		return nil, Metadata{}, false
+	}
+	return f, meta, true
+}
+
+// entry returns the index entry of a valid image id.
+func (r *FilesystemRepository) entry(id string) *fsEntry {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.index[id]
+}
+
+// remove deletes the sidecar and then the blob, so a crash in between
+// leaves an orphaned blob for the sweeper rather than metadata without
+// data.
+func (r *FilesystemRepository) remove(ctx context.Context, id string, size int) error {
+	if err := os.Remove(r.metaPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(r.blobPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	log.Ctx(ctx).Info().Str("image_id", id).Int("bytes", size).Msg("image file removed")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "images_deleted_total", map[string]string{}, 1)
+		r.reg.Inc(ctx, "images_bytes_deleted_total", map[string]string{}, int64(size))
+	}
+	return nil
+}
+
+// OnRemove implements RemoveNotifier for images removed by the background
+// sweeper. Images found expired when the repository is opened are removed
+// before fn can be set and are not reported.
+func (r *FilesystemRepository) OnRemove(fn RemoveFunc) {
+	r.mu.Lock()
+	r.onRemove = fn
+	r.mu.Unlock()
+}
+
+// Close stops the background sweeper.
+func (r *FilesystemRepository) Close() {
+	select {
+	case <-r.stopCh:
+	default:
+		close(r.stopCh)
+	}
+}
+
+func (r *FilesystemRepository) sweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.sweep(context.Background()); err != nil {
+				log.Warn().Err(err).Msg("image storage sweep failed")
+			}
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// sweep deletes expired images, blobs without metadata (e.g. written just
+// before a crash) and leftover temporary files.
+func (r *FilesystemRepository) sweep(ctx context.Context) error {
+	now := r.now()
+	r.mu.Lock()
+	expired := map[string]Metadata{}
+	for id, entry := range r.index {
+		entry.mu.Lock()
+		if entry.meta.Expired(now) {
+			expired[id] = entry.meta
+		}
+		entry.mu.Unlock()
+	}
+	onRemove := r.onRemove
+	r.mu.Unlock()
+	for id, meta := range expired {
+		if err := r.Delete(ctx, id); err != nil {
+			return err
+		}
+		if onRemove != nil {
+			onRemove(ctx, id, meta, RemovedExpired)
+		}
+	}
+
+	files, err := os.ReadDir(filepath.Join(r.dir, blobsDir))
+	if err != nil {
+		return err
+	}
+	orphans := 0
+	for _, file := range files {
+		name := file.Name()
+		r.mu.Lock()
+		_, indexed := r.index[name]
+		r.mu.Unlock()
+		if indexed {
+			continue
+		}
+		// Fresh files may belong to a Save in progress.
+		if info, err := file.Info(); err != nil || now.Sub(info.ModTime()) < orphanGrace {
+			continue
+		}
+		if err := os.Remove(filepath.Join(r.dir, blobsDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		orphans++
+	}
+
+	if len(expired) > 0 || orphans > 0 {
+		log.Info().Int("expired", len(expired)).Int("orphans", orphans).Msg("image storage swept")
+	}
+	if r.reg != nil && len(expired) > 0 {
+		r.reg.Inc(ctx, "images_expired_total", map[string]string{}, int64(len(expired)))
+	}
+	return nil
+}
+
+func (r *FilesystemRepository) blobPath(id string) string {
+	return filepath.Join(r.dir, blobsDir, id)
+}
+
+func (r *FilesystemRepository) metaPath(id string) string {
+	return filepath.Join(r.dir, metaDir, id+metaExt)
+}
+
+// writeMeta replaces the sidecar of an image.
+func (r *FilesystemRepository) writeMeta(id string, meta Metadata) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return r.writeAtomic(r.metaPath(id), data)
+}
+
+// loadMetadata reads the sidecars into the index. Sidecars of unknown ids
+// or that do not parse are removed; blobs without a sidecar are removed by
+// the first sweep.
+func (r *FilesystemRepository) loadMetadata() error {
+	files, err := os.ReadDir(filepath.Join(r.dir, metaDir))
+	if err != nil {
+		return fmt.Errorf("read image metadata: %w", err)
+	}
+	for _, file := range files {
+		id, ok := strings.CutSuffix(file.Name(), metaExt)
+		path := filepath.Join(r.dir, metaDir, file.Name())
+		var meta Metadata
+		if ok {
+			_, err = uuid.Parse(id)
+		}
+		if ok && err == nil {
+			var data []byte
+			if data, err = os.ReadFile(path); err == nil {
+				err = json.Unmarshal(data, &meta)
+			}
+		}
+		if !ok || err != nil {
+			log.Warn().Err(err).Str("file", path).Msg("dropping unreadable image metadata")
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		r.index[id] = &fsEntry{meta: meta}
+	}
+	return nil
+}
+
+// writeAtomic writes data to a temporary file in the blobs directory and
+// renames it to path, so path is either absent or complete.
+func (r *FilesystemRepository) writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Join(r.dir, blobsDir), tmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,133 @@
+package image_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	imagerepo "pod_api/pkg/repository/image"
+
+	"github.com/stretchr/testify/require"
+)
+
+func openFilesystem(t *testing.T, dir string) *imagerepo.FilesystemRepository {
+	t.Helper()
+	opts := imagerepo.NewFilesystemOptions()
+	opts.SweepInterval = 0
+	repo, err := imagerepo.NewFilesystemRepository(dir, opts)
+	require.NoError(t, err)
+	t.Cleanup(repo.Close)
+	return repo
+}
+
+func TestFilesystemRepository(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo := openFilesystem(t, dir)
+
+	kept, err := repo.Save(ctx, []byte("kept"), imagerepo.Metadata{ContentType: "image/png", RequestID: "req-1", Filename: "kept.png"}, time.Hour)
+	require.NoError(t, err)
+	expiring, err := repo.Save(ctx, []byte("expiring"), imagerepo.Metadata{}, 10*time.Millisecond)
+	require.NoError(t, err)
+	deleted, err := repo.Save(ctx, []byte("deleted"), imagerepo.Metadata{}, time.Hour)
+	require.NoError(t, err)
+	twice, err := repo.Save(ctx, []byte("twice"), imagerepo.Metadata{MaxReads: 2}, time.Hour)
+	require.NoError(t, err)
+	rdr, meta, ok := repo.Open(ctx, twice)
+	require.True(t, ok)
+	require.Zero(t, meta.Reads, "open does not count")
+	require.NoError(t, rdr.Close())
+	rdr, meta, ok = repo.Read(ctx, twice)
+	require.True(t, ok)
+	require.Equal(t, 1, meta.Reads)
+	require.NoError(t, rdr.Close())
+
+	data, ok := repo.Get(ctx, kept)
+	require.True(t, ok)
+	require.Equal(t, []byte("kept"), data)
+
+	require.NoError(t, repo.Delete(ctx, deleted))
+	_, ok = repo.Get(ctx, deleted)
+	require.False(t, ok)
+	require.FileExists(t, filepath.Join(dir, "meta", kept+".json"))
+	require.NoFileExists(t, filepath.Join(dir, "meta", deleted+".json"))
+
+	// An orphan left by a crash between the blob and sidecar writes.
+	orphan := filepath.Join(dir, "blobs", "orphan")
+	require.NoError(t, os.WriteFile(orphan, []byte("x"), 0o600))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(orphan, old, old))
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok = repo.Get(ctx, expiring)
+	require.False(t, ok, "expired images are not returned")
+
+	// Restart: the metadata survives, expired and orphaned files are swept.
+	repo.Close()
+	repo = openFilesystem(t, dir)
+
+	// The read count survives the restart; the last reader keeps its file
+	// after the image is removed.
+	rdr, _, ok = repo.Read(ctx, twice)
+	require.True(t, ok)
+	_, _, ok = repo.Read(ctx, twice)
+	require.False(t, ok)
+	require.NoFileExists(t, filepath.Join(dir, "blobs", twice))
+	require.NoFileExists(t, filepath.Join(dir, "meta", twice+".json"))
+	_, err = rdr.Seek(2, io.SeekStart)
+	require.NoError(t, err)
+	rest, err := io.ReadAll(rdr)
+	require.NoError(t, err)
+	require.Equal(t, []byte("ice"), rest)
+	require.NoError(t, rdr.Close())
+
+	data, meta, ok = repo.GetWithMeta(ctx, kept)
+	require.True(t, ok)
+	require.Equal(t, []byte("kept"), data)
+	require.Equal(t, "image/png", meta.ContentType)
+	require.Equal(t, 4, meta.Size)
+	// sha256("kept")
+	require.Equal(t, "79f076abdd19a752db7267bfff2f9022161d120dea919fdaca2ffdfc24ca8c96", meta.SHA256)
+	require.Equal(t, "req-1", meta.RequestID)
+	require.Equal(t, "kept.png", meta.Filename)
+	require.WithinDuration(t, meta.UploadedAt.Add(time.Hour), meta.ExpiresAt, time.Millisecond)
+	require.NoFileExists(t, filepath.Join(dir, "blobs", expiring))
+	require.NoFileExists(t, filepath.Join(dir, "meta", expiring+".json"))
+	require.NoFileExists(t, orphan)
+
+	_, ok = repo.Get(ctx, "../index.json")
+	require.False(t, ok)
+}
+
+func TestFilesystemRepositoryOnRemove(t *testing.T) {
+	ctx := context.Background()
+	opts := imagerepo.NewFilesystemOptions()
+	opts.SweepInterval = 5 * time.Millisecond
+	repo, err := imagerepo.NewFilesystemRepository(t.TempDir(), opts)
+	require.NoError(t, err)
+	defer repo.Close()
+	var mu sync.Mutex
+	removed := map[string]imagerepo.RemoveReason{}
+	repo.OnRemove(func(_ context.Context, id string, meta imagerepo.Metadata, reason imagerepo.RemoveReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 8, meta.Size)
+		removed[id] = reason
+	})
+
+	_, err = repo.Save(ctx, []byte("kept"), imagerepo.Metadata{}, time.Hour)
+	require.NoError(t, err)
+	expired, err := repo.Save(ctx, []byte("expiring"), imagerepo.Metadata{}, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(removed) == 1
+	}, time.Second, time.Millisecond)
+	require.Equal(t, map[string]imagerepo.RemoveReason{expired: imagerepo.RemovedExpired}, removed)
+}
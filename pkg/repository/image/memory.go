@@ -1,8 +1,10 @@
 package image
 
 import (
+	"container/list"
 	"context"
 	"errors"
+	"io"
 	"sync"
 	"time"
 
@@ -11,25 +13,80 @@ import (
 	"pod_api/pkg/metrics"
 )
 
+// Eviction selects what MemoryRepository does when a new image does not
+// fit into MemoryLimits.
+type Eviction string
+
+const (
+	// EvictLRU removes the least recently saved or read images.
+	EvictLRU Eviction = "lru"
+	// EvictOldest removes the earliest saved images.
+	EvictOldest Eviction = "oldest"
+	// EvictReject keeps stored images and fails Save with ErrStorageFull.
+	EvictReject Eviction = "reject"
+)
+
+// MemoryLimits bounds MemoryRepository; zero values mean unlimited.
+type MemoryLimits struct {
+	MaxBytes   int64
+	MaxEntries int
+	// Eviction defaults to EvictLRU.
+	Eviction Eviction
+}
+
+// RemoveReason tells why a repository dropped an image on its own.
+type RemoveReason string
+
+const (
+	// RemovedExpired images reached their TTL.
+	RemovedExpired RemoveReason = "expired"
+	// RemovedEvicted images made room for newer ones.
+	RemovedEvicted RemoveReason = "evicted"
+)
+
+// RemoveFunc is notified of images a repository removes by TTL or
+// eviction, see RemoveNotifier.
+type RemoveFunc func(ctx context.Context, id string, meta Metadata, reason RemoveReason)
+
 type imageEntry struct {
+	id    string
 	data  []byte
+	meta  Metadata
 	timer *time.Timer
+	// elem is the entry's position in MemoryRepository.order.
+	elem *list.Element
 }
 
 // MemoryRepository is an in-memory ImageRepository implementation.
 type MemoryRepository struct {
-	mu   sync.RWMutex
-	data map[string]*imageEntry
-	reg  *metrics.Registry
+	mu     sync.Mutex
+	data   map[string]*imageEntry
+	order  *list.List // front is the most recent entry
+	bytes  int64
+	limits MemoryLimits
+	reg    *metrics.Registry
+	// onRemove is optional, see OnRemove.
+	onRemove RemoveFunc
 }
 
-// NewMemoryRepository creates an empty in-memory repository.
-func NewMemoryRepository(reg *metrics.Registry) *MemoryRepository {
-	return &MemoryRepository{data: make(map[string]*imageEntry), reg: reg}
+// NewMemoryRepository creates an empty in-memory repository bounded by
+// limits.
+func NewMemoryRepository(reg *metrics.Registry, limits MemoryLimits) *MemoryRepository {
+	if limits.Eviction == "" {
+		limits.Eviction = EvictLRU
+	}
+	return &MemoryRepository{
+		data:   make(map[string]*imageEntry),
+		order:  list.New(),
+		limits: limits,
+		reg:    reg,
+	}
 }
 
 // Save stores image bytes under a new UUID with TTL-based auto-deletion.
-func (r *MemoryRepository) Save(ctx context.Context, b []byte, ttl time.Duration) (string, error) {
+// When the image does not fit into the limits, older images are evicted
+// or, with EvictReject, ErrStorageFull is returned.
+func (r *MemoryRepository) Save(ctx context.Context, b []byte, meta Metadata, ttl time.Duration) (string, error) {
 	if len(b) == 0 {
 		return "", errors.New("empty image data")
 	}
@@ -40,25 +97,47 @@ func (r *MemoryRepository) Save(ctx context.Context, b []byte, ttl time.Duration
 	copyBuf := make([]byte, len(b))
 	copy(copyBuf, b)
 
-	entry := &imageEntry{}
-	// Create the timer first, but don't start until after storing.
+	entry := &imageEntry{id: id, data: copyBuf, meta: describe(copyBuf, meta, ttl, time.Now())}
+
+	r.mu.Lock()
+	evicted, ok := r.makeRoom(int64(len(copyBuf)))
+	if !ok {
+		r.mu.Unlock()
+		log.Ctx(ctx).Warn().Int("bytes", len(copyBuf)).Msg("image storage is full")
+		if r.reg != nil {
+			r.reg.Inc(ctx, "images_rejected_total", map[string]string{}, 1)
+		}
+		return "", ErrStorageFull
+	}
+	// Create the timer under the lock so Delete cannot run before it is set.
 	if ttl > 0 {
 		entry.timer = time.AfterFunc(ttl, func() {
-			// Background deletion; context not required.
-			_ = r.Delete(context.Background(), id)
+			r.expire(id)
 		})
 	}
-	entry.data = copyBuf
-
-	r.mu.Lock()
+	entry.elem = r.order.PushFront(entry)
 	r.data[id] = entry
+	r.bytes += int64(len(copyBuf))
+	r.reportUsage(ctx)
+	onRemove := r.onRemove
 	r.mu.Unlock()
 
 	// Log and metrics
 	log.Ctx(ctx).Info().Str("image_id", id).Int("bytes", len(copyBuf)).Msg("image saved to memory")
+	for _, e := range evicted {
+		log.Ctx(ctx).Info().Str("image_id", e.id).Int("bytes", len(e.data)).Msg("image evicted from memory")
+	}
 	if r.reg != nil {
 		r.reg.Inc(ctx, "images_saved_total", map[string]string{}, 1)
 		r.reg.Inc(ctx, "images_bytes_stored_total", map[string]string{}, int64(len(copyBuf)))
+		if len(evicted) > 0 {
+			r.reg.Inc(ctx, "images_evicted_total", map[string]string{"policy": string(r.limits.Eviction)}, int64(len(evicted)))
+		}
+	}
+	if onRemove != nil {
+		for _, e := range evicted {
+			onRemove(ctx, e.id, e.meta, RemovedEvicted)
+		}
 	}
 
 	return id, nil
@@ -66,15 +145,77 @@ func (r *MemoryRepository) Save(ctx context.Context, b []byte, ttl time.Duration
 
 // Get returns a copy of stored data by id without deleting it.
 func (r *MemoryRepository) Get(ctx context.Context, id string) ([]byte, bool) {
-	r.mu.RLock()
+	data, _, ok := r.GetWithMeta(ctx, id)
+	return data, ok
+}
+
+// GetWithMeta returns a copy of stored data and its metadata. With
+// EvictLRU reading an image protects it from eviction.
+func (r *MemoryRepository) GetWithMeta(ctx context.Context, id string) ([]byte, Metadata, bool) {
+	r.mu.Lock()
 	e, ok := r.data[id]
-	r.mu.RUnlock()
-	if !ok || e == nil || len(e.data) == 0 {
-		return nil, false
+	if ok && r.limits.Eviction == EvictLRU {
+		r.order.MoveToFront(e.elem)
+	}
+	r.mu.Unlock()
+	if !ok || len(e.data) == 0 {
+		return nil, Metadata{}, false
 	}
 	out := make([]byte, len(e.data))
 	copy(out, e.data)
-	return out, true
+	return out, e.meta, true
+}
+
+// Read returns a reader over the stored data and counts the read; the last
+// allowed read removes the image.
+func (r *MemoryRepository) Read(ctx context.Context, id string) (io.ReadSeekCloser, Metadata, bool) {
+	r.mu.Lock()
+	e, ok := r.data[id]
+	if !ok {
+		r.mu.Unlock()
+		return nil, Metadata{}, false
+	}
+	e.meta.Reads++
+	meta := e.meta
+	exhausted := meta.MaxReads > 0 && meta.Reads >= meta.MaxReads
+	if exhausted {
+		r.remove(e)
+		r.reportUsage(ctx)
+	} else if r.limits.Eviction == EvictLRU {
+		r.order.MoveToFront(e.elem)
+	}
+	r.mu.Unlock()
+
+	if exhausted {
+		r.deleted(ctx, e)
+	}
+	// Stored bytes are never modified, so the reader shares them.
+	return newBytesReader(e.data), meta, true
+}
+
+// Open returns a reader over the stored data without counting a read.
+func (r *MemoryRepository) Open(_ context.Context, id string) (io.ReadSeekCloser, Metadata, bool) {
+	r.mu.Lock()
+	e, ok := r.data[id]
+	if ok && r.limits.Eviction == EvictLRU {
+		r.order.MoveToFront(e.elem)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return nil, Metadata{}, false
+	}
+	return newBytesReader(e.data), e.meta, true
+}
+
+// Stat returns the metadata of a stored image.
+func (r *MemoryRepository) Stat(ctx context.Context, id string) (Metadata, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.data[id]
+	if !ok {
+		return Metadata{}, false
+	}
+	return e.meta, true
 }
 
 // Delete stops the TTL timer and removes the entry from memory.
@@ -82,21 +223,99 @@ func (r *MemoryRepository) Delete(ctx context.Context, id string) error {
 	r.mu.Lock()
 	e, ok := r.data[id]
 	if ok {
-		delete(r.data, id)
+		r.remove(e)
+		r.reportUsage(ctx)
+	}
+	r.mu.Unlock()
+
+	if ok {
+		r.deleted(ctx, e)
+	}
+	return nil
+}
+
+// OnRemove implements RemoveNotifier for images removed by TTL or
+// eviction.
+func (r *MemoryRepository) OnRemove(fn RemoveFunc) {
+	r.mu.Lock()
+	r.onRemove = fn
+	r.mu.Unlock()
+}
+
+// expire removes an image whose TTL has passed.
+func (r *MemoryRepository) expire(id string) {
+	// Background deletion; the request context is gone.
+	ctx := context.Background()
+	r.mu.Lock()
+	e, ok := r.data[id]
+	var meta Metadata
+	if ok {
+		meta = e.meta
+		r.remove(e)
+		r.reportUsage(ctx)
 	}
+	onRemove := r.onRemove
 	r.mu.Unlock()
 
-	if ok && e != nil && e.timer != nil {
+	if !ok {
+		return
+	}
+	r.deleted(ctx, e)
+	if onRemove != nil {
+		onRemove(ctx, id, meta, RemovedExpired)
+	}
+}
+
+func (r *MemoryRepository) deleted(ctx context.Context, e *imageEntry) {
+	size := len(e.data)
+	log.Ctx(ctx).Info().Str("image_id", e.id).Int("bytes", size).Msg("image memory freed")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "images_deleted_total", map[string]string{}, 1)
+		r.reg.Inc(ctx, "images_bytes_deleted_total", map[string]string{}, int64(size))
+	}
+}
+
+// makeRoom evicts entries until an image of size fits into the limits and
+// returns the evicted entries; ok is false when the image cannot be stored.
+// r.mu must be held.
+func (r *MemoryRepository) makeRoom(size int64) (evicted []*imageEntry, ok bool) {
+	if r.limits.MaxBytes > 0 && size > r.limits.MaxBytes {
+		return nil, false
+	}
+	fits := func() bool {
+		return (r.limits.MaxBytes <= 0 || r.bytes+size <= r.limits.MaxBytes) &&
+			(r.limits.MaxEntries <= 0 || len(r.data) < r.limits.MaxEntries)
+	}
+	if fits() {
+		return nil, true
+	}
+	if r.limits.Eviction == EvictReject {
+		return nil, false
+	}
+	for !fits() {
+		e := r.order.Back().Value.(*imageEntry)
+		r.remove(e)
+		evicted = append(evicted, e)
+	}
+	return evicted, true
+}
+
+// remove drops the entry and stops its timer; r.mu must be held.
+func (r *MemoryRepository) remove(e *imageEntry) {
+	if e.timer != nil {
 		e.timer.Stop()
 	}
+	delete(r.data, e.id)
+	r.order.Remove(e.elem)
+	r.bytes -= int64(len(e.data))
+}
 
-	if ok && e != nil {
-		size := len(e.data)
-		log.Ctx(ctx).Info().Str("image_id", id).Int("bytes", size).Msg("image memory freed")
-		if r.reg != nil {
-			r.reg.Inc(ctx, "images_deleted_total", map[string]string{}, 1)
-			r.reg.Inc(ctx, "images_bytes_deleted_total", map[string]string{}, int64(size))
-		}
+// reportUsage updates the usage gauges; r.mu must be held so that updates
+// are not reordered.
+func (r *MemoryRepository) reportUsage(ctx context.Context) {
+	if r.reg == nil {
+		return
 	}
-	return nil
+	r.reg.Set(ctx, "images_memory_entries", map[string]string{}, int64(len(r.data)))
+	r.reg.Set(ctx, "images_memory_bytes", map[string]string{}, r.bytes)
 }
@@ -0,0 +1,139 @@
+package image_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pod_api/pkg/metrics"
+	imagerepo "pod_api/pkg/repository/image"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepositoryEviction(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		eviction imagerepo.Eviction
+		// kept reports which of the images a, b, c, d remain.
+		kept [4]bool
+	}{
+		// a was read after c was saved, so b and c are less recently used.
+		{eviction: imagerepo.EvictLRU, kept: [4]bool{true, false, false, true}},
+		{eviction: imagerepo.EvictOldest, kept: [4]bool{false, false, true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.eviction), func(t *testing.T) {
+			repo := imagerepo.NewMemoryRepository(nil, imagerepo.MemoryLimits{MaxBytes: 10, MaxEntries: 3, Eviction: tt.eviction})
+			var ids []string
+			for _, data := range []string{"aaa", "bbb", "cc"} {
+				id, err := repo.Save(ctx, []byte(data), imagerepo.Metadata{}, time.Hour)
+				require.NoError(t, err)
+				ids = append(ids, id)
+			}
+			_, ok := repo.Get(ctx, ids[0])
+			require.True(t, ok)
+
+			// d needs both a free entry and 6 bytes, so two images go.
+			id, err := repo.Save(ctx, []byte("dddddd"), imagerepo.Metadata{}, time.Hour)
+			require.NoError(t, err)
+			ids = append(ids, id)
+
+			for i, id := range ids {
+				_, ok := repo.Get(ctx, id)
+				require.Equal(t, tt.kept[i], ok, "image %d", i)
+			}
+		})
+	}
+}
+
+func TestMemoryRepositoryReject(t *testing.T) {
+	ctx := context.Background()
+	reg := metrics.NewRegistry()
+	repo := imagerepo.NewMemoryRepository(reg, imagerepo.MemoryLimits{MaxBytes: 8, Eviction: imagerepo.EvictReject})
+
+	first, err := repo.Save(ctx, []byte("12345"), imagerepo.Metadata{}, time.Hour)
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, []byte("12345"), imagerepo.Metadata{}, time.Hour)
+	require.ErrorIs(t, err, imagerepo.ErrStorageFull)
+	_, err = repo.Save(ctx, []byte("123456789"), imagerepo.Metadata{}, time.Hour)
+	require.ErrorIs(t, err, imagerepo.ErrStorageFull, "larger than the whole budget")
+
+	_, ok := repo.Get(ctx, first)
+	require.True(t, ok)
+	require.Equal(t, int64(5), reg.SnapshotJSON()["images_memory_bytes"])
+	require.Equal(t, int64(1), reg.SnapshotJSON()["images_memory_entries"])
+
+	require.NoError(t, repo.Delete(ctx, first))
+	_, err = repo.Save(ctx, []byte("1234567"), imagerepo.Metadata{}, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), reg.SnapshotJSON()["images_memory_bytes"])
+}
+
+func TestMemoryRepositoryReadPolicy(t *testing.T) {
+	ctx := context.Background()
+	repo := imagerepo.NewMemoryRepository(nil, imagerepo.MemoryLimits{})
+
+	limited, err := repo.Save(ctx, []byte("limited"), imagerepo.Metadata{MaxReads: 3}, time.Hour)
+	require.NoError(t, err)
+	for range 5 {
+		_, meta, ok := repo.Open(ctx, limited)
+		require.True(t, ok)
+		require.Zero(t, meta.Reads, "open does not count")
+	}
+	var reads atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, ok := repo.Read(ctx, limited); ok {
+				reads.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(3), reads.Load())
+	_, ok := repo.Stat(ctx, limited)
+	require.False(t, ok, "exhausted images are deleted")
+
+	untilTTL, err := repo.Save(ctx, []byte("ttl"), imagerepo.Metadata{}, time.Hour)
+	require.NoError(t, err)
+	for range 5 {
+		_, _, ok := repo.Read(ctx, untilTTL)
+		require.True(t, ok)
+	}
+	meta, ok := repo.Stat(ctx, untilTTL)
+	require.True(t, ok)
+	require.Equal(t, 5, meta.Reads)
+}
+
+func TestMemoryRepositoryOnRemove(t *testing.T) {
+	ctx := context.Background()
+	repo := imagerepo.NewMemoryRepository(nil, imagerepo.MemoryLimits{MaxEntries: 1})
+	var mu sync.Mutex
+	removed := map[string]imagerepo.RemoveReason{}
+	repo.OnRemove(func(_ context.Context, id string, meta imagerepo.Metadata, reason imagerepo.RemoveReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 3, meta.Size)
+		removed[id] = reason
+	})
+
+	evicted, err := repo.Save(ctx, []byte("aaa"), imagerepo.Metadata{}, time.Hour)
+	require.NoError(t, err)
+	expired, err := repo.Save(ctx, []byte("bbb"), imagerepo.Metadata{}, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(removed) == 2
+	}, time.Second, time.Millisecond)
+	require.Equal(t, imagerepo.RemovedEvicted, removed[evicted])
+	require.Equal(t, imagerepo.RemovedExpired, removed[expired])
+	_, ok := repo.Stat(ctx, expired)
+	require.False(t, ok)
+}
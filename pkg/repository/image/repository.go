@@ -1,18 +1,112 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
 	"time"
 )
 
 // ImageRepository defines methods for temporary in-memory image storage
 // with automatic resource cleanup via TTL.
 type ImageRepository interface {
-	// Save stores image bytes and returns a UUID identifier.
+	// Save stores image bytes and returns a UUID identifier. meta supplies
+	// ContentType, RequestID, Filename and MaxReads; the rest is filled by
+	// Save.
 	// ttl defines how long the image should be kept in memory.
-	Save(ctx context.Context, data []byte, ttl time.Duration) (string, error)
+	Save(ctx context.Context, data []byte, meta Metadata, ttl time.Duration) (string, error)
 	// Get returns a copy of the image by id. The boolean indicates presence.
 	Get(ctx context.Context, id string) ([]byte, bool)
+	// GetWithMeta returns a copy of the image along with its metadata.
+	GetWithMeta(ctx context.Context, id string) ([]byte, Metadata, bool)
+	// Stat returns the image metadata without reading the image.
+	Stat(ctx context.Context, id string) (Metadata, bool)
+	// Read opens the image for reading and counts the read against
+	// Metadata.MaxReads. The read that exhausts MaxReads deletes the image,
+	// so concurrent readers never get more than MaxReads copies; the
+	// returned reader stays valid until closed.
+	Read(ctx context.Context, id string) (io.ReadSeekCloser, Metadata, bool)
+	// Open opens the image for reading without counting a read, for
+	// partial downloads that do not reach the end of the image.
+	Open(ctx context.Context, id string) (io.ReadSeekCloser, Metadata, bool)
 	// Delete removes an image before TTL expiration.
 	Delete(ctx context.Context, id string) error
 }
+
+// Metadata is the record stored alongside an image.
+type Metadata struct {
+	ContentType string `json:"content_type,omitempty"`
+	Size        int    `json:"size"`
+	// SHA256 is the hex-encoded digest of the image bytes.
+	SHA256     string    `json:"sha256,omitempty"`
+	UploadedAt time.Time `json:"uploaded_at,omitzero"`
+	// ExpiresAt is zero for images without TTL.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	// RequestID is the X-Request-ID of the upload.
+	RequestID string `json:"request_id,omitempty"`
+	// Filename is the original name of the uploaded file.
+	Filename string `json:"filename,omitempty"`
+	// MaxReads is the read policy: 1 for single-use images, N for N reads,
+	// 0 to keep the image until its TTL.
+	MaxReads int `json:"max_reads,omitempty"`
+	// Reads counts reads taken with Read.
+	Reads int `json:"reads,omitempty"`
+}
+
+// Expired reports whether the image is past its TTL at now.
+func (m Metadata) Expired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
+}
+
+// describe completes meta for data saved at now.
+func describe(data []byte, meta Metadata, ttl time.Duration, now time.Time) Metadata {
+	sum := sha256.Sum256(data)
+	meta.Size = len(data)
+	meta.SHA256 = hex.EncodeToString(sum[:])
+	meta.UploadedAt = now.UTC()
+	meta.Reads = 0
+	meta.ExpiresAt = time.Time{}
+	if ttl > 0 {
+		meta.ExpiresAt = meta.UploadedAt.Add(ttl)
+	}
+	if meta.ContentType == "" {
+		meta.ContentType = http.DetectContentType(data)
+	}
+	return meta
+}
+
+// URLPresigner is implemented by repositories whose images can be fetched
+// by vision providers directly, bypassing GET /api/v1/images/{id}.
+type URLPresigner interface {
+	// PresignGet returns a temporary link to the image.
+	PresignGet(ctx context.Context, id string) (string, error)
+}
+
+// RemoveNotifier is implemented by repositories that report images they
+// remove on their own, by TTL or eviction. Removals by Read and Delete are
+// made by the caller and not reported.
+type RemoveNotifier interface {
+	// OnRemove sets fn to be notified of such removals.
+	OnRemove(fn RemoveFunc)
+}
+
+// ErrStorageFull is returned by Save when the image does not fit into the
+// storage limits.
+var ErrStorageFull = errors.New("image storage is full")
+
+// bytesReader serves image bytes kept in memory as an io.ReadSeekCloser.
+type bytesReader struct {
+	*bytes.Reader
+}
+
+func newBytesReader(data []byte) bytesReader {
+	return bytesReader{Reader: bytes.NewReader(data)}
+}
+
+func (bytesReader) Close() error {
+	return nil
+}
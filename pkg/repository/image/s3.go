@@ -0,0 +1,716 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/metrics"
+)
+
+// Object metadata headers. S3 lifecycle rules work in whole days, so the
+// repository enforces the TTL stored in expiresAtHeader on read and
+// removes expired objects with a background sweeper.
+const (
+	expiresAtHeader  = "X-Amz-Meta-Expires-At"
+	uploadedAtHeader = "X-Amz-Meta-Uploaded-At"
+	sha256Header     = "X-Amz-Meta-Sha256"
+	maxReadsHeader   = "X-Amz-Meta-Max-Reads"
+	// Free-form values are URL-escaped: S3 metadata must be ASCII.
+	requestIDHeader = "X-Amz-Meta-Request-Id"
+	filenameHeader  = "X-Amz-Meta-Filename"
+)
+
+// S3Options configures NewS3Repository.
+type S3Options struct {
+	// Endpoint is the S3 API root, e.g. https://storage.yandexcloud.net or
+	// http://localhost:9000 for MinIO.
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to object keys, e.g. "images/".
+	Prefix string
+
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// PathStyle addresses objects as <endpoint>/<bucket>/<key> instead of
+	// <bucket>.<endpoint host>/<key>; MinIO and most self-hosted servers
+	// need it.
+	PathStyle bool
+
+	// PresignTTL is how long presigned links handed to providers are valid;
+	// a link never outlives the image TTL.
+	PresignTTL time.Duration
+
+	// SweepInterval is how often expired objects are listed and removed
+	// (0 disables the background sweeper; expired images are still never
+	// returned).
+	SweepInterval time.Duration
+
+	// HTTPClient is optional; nil uses a client with a 30s timeout.
+	HTTPClient *http.Client
+
+	// Metrics is optional; nil disables repository metrics.
+	Metrics *metrics.Registry
+}
+
+// NewS3Options returns sensible defaults.
+func NewS3Options() S3Options {
+	return S3Options{
+		Region:        "us-east-1",
+		PathStyle:     true,
+		PresignTTL:    5 * time.Minute,
+		SweepInterval: time.Minute,
+	}
+}
+
+// S3Repository is an ImageRepository backed by an S3-compatible object
+// storage, so every replica sees the same images. It also implements
+// URLPresigner.
+type S3Repository struct {
+	endpoint   *url.URL
+	bucket     string
+	prefix     string
+	pathStyle  bool
+	presignTTL time.Duration
+	signer     signer
+	client     *http.Client
+	reg        *metrics.Registry
+	now        func() time.Time
+	stopCh     chan struct{}
+
+	mu sync.Mutex
+	// onRemove is optional, see OnRemove.
+	onRemove RemoveFunc
+}
+
+// NewS3Repository validates opts, constructs S3Repository and starts the
+// background sweeper. The bucket must exist; it is not created. Read
+// counting needs conditional writes, so a storage that ignores
+// If-None-Match is rejected.
+func NewS3Repository(opts S3Options) (*S3Repository, error) {
+	if opts.Endpoint == "" {
+		return nil, errors.New("s3 endpoint should not be empty")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", opts.Endpoint)
+	}
+	if opts.Bucket == "" {
+		return nil, errors.New("s3 bucket should not be empty")
+	}
+	if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, errors.New("s3 credentials should not be empty")
+	}
+	if opts.Region == "" {
+		opts.Region = NewS3Options().Region
+	}
+	if opts.PresignTTL <= 0 {
+		opts.PresignTTL = NewS3Options().PresignTTL
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	r := &S3Repository{
+		endpoint:   endpoint,
+		bucket:     opts.Bucket,
+		prefix:     opts.Prefix,
+		pathStyle:  opts.PathStyle,
+		presignTTL: opts.PresignTTL,
+		signer:     signer{accessKey: opts.AccessKeyID, secretKey: opts.SecretAccessKey, region: opts.Region},
+		client:     client,
+		reg:        opts.Metrics,
+		now:        time.Now,
+		stopCh:     make(chan struct{}),
+	}
+	if err := r.checkConditionalWrites(context.Background()); err != nil {
+		return nil, err
+	}
+	if opts.SweepInterval > 0 {
+		go r.sweeper(opts.SweepInterval)
+	}
+	return r, nil
+}
+
+// Save uploads image bytes under a new UUID; the metadata is stored with
+// the object.
+func (r *S3Repository) Save(ctx context.Context, b []byte, meta Metadata, ttl time.Duration) (string, error) {
+	if len(b) == 0 {
+		return "", errors.New("empty image data")
+	}
+
+	id := uuid.NewString()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, r.objectURL(id).String(), bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	meta = describe(b, meta, ttl, r.now())
+	req.Header.Set("Content-Type", meta.ContentType)
+	req.Header.Set(uploadedAtHeader, meta.UploadedAt.Format(time.RFC3339Nano))
+	req.Header.Set(sha256Header, meta.SHA256)
+	if !meta.ExpiresAt.IsZero() {
+		req.Header.Set(expiresAtHeader, meta.ExpiresAt.Format(time.RFC3339Nano))
+	}
+	if meta.RequestID != "" {
+		req.Header.Set(requestIDHeader, url.PathEscape(meta.RequestID))
+	}
+	if meta.Filename != "" {
+		req.Header.Set(filenameHeader, url.PathEscape(meta.Filename))
+	}
+	if meta.MaxReads > 0 {
+		req.Header.Set(maxReadsHeader, strconv.Itoa(meta.MaxReads))
+	}
+	resp, err := r.do(req, hashHex(b))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	log.Ctx(ctx).Info().Str("image_id", id).Int("bytes", len(b)).Msg("image saved to s3")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "images_saved_total", map[string]string{}, 1)
+		r.reg.Inc(ctx, "images_bytes_stored_total", map[string]string{}, int64(len(b)))
+	}
+	return id, nil
+}
+
+// Get downloads the image by id. Expired images are reported as missing
+// and removed.
+func (r *S3Repository) Get(ctx context.Context, id string) ([]byte, bool) {
+	data, _, ok := r.GetWithMeta(ctx, id)
+	return data, ok
+}
+
+// GetWithMeta downloads the image and reads its metadata from the object
+// headers.
+func (r *S3Repository) GetWithMeta(ctx context.Context, id string) ([]byte, Metadata, bool) {
+	resp, meta, ok := r.fetch(ctx, http.MethodGet, id)
+	if !ok {
+		return nil, Metadata{}, false
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image read failed")
+		return nil, Metadata{}, false
+	}
+	meta.Size = len(data)
+	return data, meta, true
+}
+
+// Stat reads the image metadata with a HEAD request.
+func (r *S3Repository) Stat(ctx context.Context, id string) (Metadata, bool) {
+	resp, meta, ok := r.fetch(ctx, http.MethodHead, id)
+	if !ok {
+		return Metadata{}, false
+	}
+	resp.Body.Close()
+	return meta, true
+}
+
+// fetch sends a GET or HEAD request for the object. Expired objects are
+// removed and reported as missing.
+func (r *S3Repository) fetch(ctx context.Context, method, id string) (*http.Response, Metadata, bool) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, Metadata{}, false
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.objectURL(id).String(), nil)
+	if err != nil {
+		return nil, Metadata{}, false
+	}
+	resp, err := r.do(req, emptyPayloadHash)
+	if err != nil {
+		if !errors.Is(err, errObjectNotFound) {
+			log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image read failed")
+		}
+		return nil, Metadata{}, false
+	}
+
+	meta := objectMetadata(resp)
+	if meta.Expired(r.now()) {
+		resp.Body.Close()
+		_ = r.Delete(context.WithoutCancel(ctx), id)
+		return nil, Metadata{}, false
+	}
+	return resp, meta, true
+}
+
+// objectMetadata restores Metadata from the object headers; fields that
+// are missing or malformed stay empty.
+func objectMetadata(resp *http.Response) Metadata {
+	meta := Metadata{
+		ContentType: resp.Header.Get("Content-Type"),
+		SHA256:      resp.Header.Get(sha256Header),
+	}
+	if resp.ContentLength > 0 {
+		meta.Size = int(resp.ContentLength)
+	}
+	meta.UploadedAt, _ = time.Parse(time.RFC3339Nano, resp.Header.Get(uploadedAtHeader))
+	meta.ExpiresAt, _ = time.Parse(time.RFC3339Nano, resp.Header.Get(expiresAtHeader))
+	meta.RequestID, _ = url.PathUnescape(resp.Header.Get(requestIDHeader))
+	meta.Filename, _ = url.PathUnescape(resp.Header.Get(filenameHeader))
+	meta.MaxReads, _ = strconv.Atoi(resp.Header.Get(maxReadsHeader))
+	return meta
+}
+
+// Read claims a read and returns a reader that downloads the object on
+// demand with ranged GETs, so serving a Range does not fetch the whole
+// image. Objects carry no atomic counter, so read n is claimed by creating
+// the marker object "<id>.reads/<n>" with If-None-Match: *, which succeeds
+// for exactly one caller. The read that exhausts MaxReads removes the
+// image, with its markers, when the reader is closed; a late reader that
+// claims a marker after that finds the image gone and is refused.
+func (r *S3Repository) Read(ctx context.Context, id string) (io.ReadSeekCloser, Metadata, bool) {
+	meta, ok := r.Stat(ctx, id)
+	if !ok {
+		return nil, Metadata{}, false
+	}
+	if meta.MaxReads == 0 {
+		return r.newObjectReader(ctx, id, meta, nil), meta, true
+	}
+
+	for n := 1; n <= meta.MaxReads; n++ {
+		err := r.createMarker(ctx, markerPrefix(id)+strconv.Itoa(n))
+		if errors.Is(err, errPreconditionFailed) {
+			continue
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image read claim failed")
+			return nil, Metadata{}, false
+		}
+		if _, ok := r.Stat(ctx, id); !ok {
+			// Exhausted or deleted between the lookup and the claim.
+			_ = r.deleteMarkers(context.WithoutCancel(ctx), id)
+			return nil, Metadata{}, false
+		}
+		meta.Reads = n
+		var onClose func()
+		if n == meta.MaxReads {
+			onClose = func() {
+				if err := r.Delete(context.WithoutCancel(ctx), id); err != nil {
+					log.Ctx(ctx).Error().Err(err).Str("image_id", id).Msg("image removal failed")
+				}
+			}
+		}
+		return r.newObjectReader(ctx, id, meta, onClose), meta, true
+	}
+	// All reads were taken by concurrent readers.
+	return nil, Metadata{}, false
+}
+
+// Open returns a reader over the object without claiming a read.
+func (r *S3Repository) Open(ctx context.Context, id string) (io.ReadSeekCloser, Metadata, bool) {
+	meta, ok := r.Stat(ctx, id)
+	if !ok {
+		return nil, Metadata{}, false
+	}
+	return r.newObjectReader(ctx, id, meta, nil), meta, true
+}
+
+// objectReader reads an object with ranged GETs: Seek only moves the
+// offset, and the next Read requests the object from there on.
+type objectReader struct {
+	ctx     context.Context
+	repo    *S3Repository
+	id      string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+	onClose func()
+}
+
+func (r *S3Repository) newObjectReader(ctx context.Context, id string, meta Metadata, onClose func()) *objectReader {
+	return &objectReader{ctx: ctx, repo: r, id: id, size: int64(meta.Size), onClose: onClose}
+}
+
+func (o *objectReader) Read(p []byte) (int, error) {
+	if o.size > 0 && o.offset >= o.size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		req, err := http.NewRequestWithContext(o.ctx, http.MethodGet, o.repo.objectURL(o.id).String(), nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", o.offset))
+		resp, err := o.repo.do(req, emptyPayloadHash)
+		if err != nil {
+			return 0, err
+		}
+		if err := o.skipToOffset(resp); err != nil {
+			resp.Body.Close()
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+// skipToOffset makes sure resp.Body starts at the offset: a partial
+// response must cover it, and a server ignoring Range sends the whole
+// object, whose head is discarded.
+func (o *objectReader) skipToOffset(resp *http.Response) error {
+	if o.offset == 0 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		contentRange := resp.Header.Get("Content-Range")
+		if !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", o.offset)) {
+			return fmt.Errorf("s3 GET %s: Content-Range %q does not start at %d", resp.Request.URL.Path, contentRange, o.offset)
+		}
+		return nil
+	}
+	if _, err := io.CopyN(io.Discard, resp.Body, o.offset); err != nil {
+		return fmt.Errorf("s3 GET %s: skip to %d: %w", resp.Request.URL.Path, o.offset, err)
+	}
+	return nil
+}
+
+func (o *objectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	if offset != o.offset && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.offset = offset
+	return offset, nil
+}
+
+// Close aborts the download and runs the deferred removal, if any.
+func (o *objectReader) Close() error {
+	var err error
+	if o.body != nil {
+		err = o.body.Close()
+		o.body = nil
+	}
+	if o.onClose != nil {
+		o.onClose()
+		o.onClose = nil
+	}
+	return err
+}
+
+// createMarker creates an empty object unless it already exists.
+func (r *S3Repository) createMarker(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, r.objectURL(name).String(), http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("If-None-Match", "*")
+	resp, err := r.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Delete removes the object and then its read markers; deleting a missing
+// image is not an error.
+func (r *S3Repository) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil
+	}
+	err := r.deleteObject(ctx, id)
+	if errors.Is(err, errObjectNotFound) {
+		return r.deleteMarkers(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().Str("image_id", id).Msg("image removed from s3")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "images_deleted_total", map[string]string{}, 1)
+	}
+	return r.deleteMarkers(ctx, id)
+}
+
+// deleteMarkers removes the read markers of an image.
+func (r *S3Repository) deleteMarkers(ctx context.Context, id string) error {
+	return r.list(ctx, r.prefix+markerPrefix(id), func(key string) error {
+		err := r.deleteObject(ctx, strings.TrimPrefix(key, r.prefix))
+		if errors.Is(err, errObjectNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// deleteObject removes the object name under the prefix.
+func (r *S3Repository) deleteObject(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, r.objectURL(name).String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// markerPrefix is the key prefix of the read markers of an image.
+func markerPrefix(id string) string {
+	return id + ".reads/"
+}
+
+// checkConditionalWrites creates a probe object twice with
+// If-None-Match: *; a storage that accepts the second write would hand
+// out every read to every concurrent reader.
+func (r *S3Repository) checkConditionalWrites(ctx context.Context) error {
+	probe := ".conditional-write-check-" + uuid.NewString()
+	if err := r.createMarker(ctx, probe); err != nil {
+		return fmt.Errorf("s3 storage check: %w", err)
+	}
+	defer func() { _ = r.deleteObject(ctx, probe) }()
+	err := r.createMarker(ctx, probe)
+	if errors.Is(err, errPreconditionFailed) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("s3 storage check: %w", err)
+	}
+	return errors.New("s3 storage ignores If-None-Match conditional writes, which image read counting needs")
+}
+
+// PresignGet returns a link that lets anyone download the image directly
+// from the storage for PresignTTL, or until the image expires if that is
+// sooner.
+func (r *S3Repository) PresignGet(ctx context.Context, id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("invalid image id %q", id)
+	}
+	meta, ok := r.Stat(ctx, id)
+	if !ok {
+		return "", fmt.Errorf("image %q not found", id)
+	}
+	now := r.now()
+	ttl := r.presignTTL
+	if !meta.ExpiresAt.IsZero() {
+		// Links are valid for whole seconds; round down so the link dies
+		// with the image.
+		ttl = min(ttl, meta.ExpiresAt.Sub(now).Truncate(time.Second))
+	}
+	if ttl < time.Second {
+		return "", fmt.Errorf("image %q expires too soon to presign", id)
+	}
+	return r.signer.presign(http.MethodGet, r.objectURL(id), ttl, now), nil
+}
+
+// OnRemove implements RemoveNotifier for images removed by the background
+// sweeper. Replicas sweep independently, so an image may be reported by
+// more than one of them.
+func (r *S3Repository) OnRemove(fn RemoveFunc) {
+	r.mu.Lock()
+	r.onRemove = fn
+	r.mu.Unlock()
+}
+
+// Close stops the background sweeper.
+func (r *S3Repository) Close() {
+	select {
+	case <-r.stopCh:
+	default:
+		close(r.stopCh)
+	}
+}
+
+func (r *S3Repository) sweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.sweep(context.Background()); err != nil {
+				log.Warn().Err(err).Msg("s3 image storage sweep failed")
+			}
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// sweep lists the images under the prefix and removes the expired ones
+// and read markers left without their image. Listings carry no user
+// metadata, so every image is checked with a HEAD request; images are
+// short-lived, which keeps the bucket small.
+func (r *S3Repository) sweep(ctx context.Context) error {
+	expired := 0
+	// Keys are listed in order, so an image precedes its markers.
+	lastImage := ""
+	err := r.list(ctx, r.prefix, func(key string) error {
+		id := strings.TrimPrefix(key, r.prefix)
+		if image, _, ok := strings.Cut(id, ".reads/"); ok {
+			if _, err := uuid.Parse(image); err != nil || image == lastImage {
+				return nil
+			}
+			if _, ok := r.Stat(ctx, image); ok {
+				// Saved after the listing passed it.
+				return nil
+			}
+			err := r.deleteObject(ctx, id)
+			if errors.Is(err, errObjectNotFound) {
+				return nil
+			}
+			return err
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return nil
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, r.objectURL(id).String(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := r.do(req, emptyPayloadHash)
+		if errors.Is(err, errObjectNotFound) {
+			// Removed since the listing.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		meta := objectMetadata(resp)
+		if !meta.Expired(r.now()) {
+			lastImage = id
+			return nil
+		}
+		if err := r.Delete(ctx, id); err != nil {
+			return err
+		}
+		expired++
+		r.mu.Lock()
+		onRemove := r.onRemove
+		r.mu.Unlock()
+		if onRemove != nil {
+			onRemove(ctx, id, meta, RemovedExpired)
+		}
+		return nil
+	})
+	if expired > 0 {
+		log.Info().Int("expired", expired).Msg("s3 image storage swept")
+		if r.reg != nil {
+			r.reg.Inc(ctx, "images_expired_total", map[string]string{}, int64(expired))
+		}
+	}
+	return err
+}
+
+// listBucketResult is the response of ListObjectsV2.
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list calls fn for every object key starting with prefix, page by page.
+func (r *S3Repository) list(ctx context.Context, prefix string, fn func(key string) error) error {
+	token := ""
+	for {
+		u := r.bucketURL()
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = query.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := r.do(req, emptyPayloadHash)
+		if err != nil {
+			return err
+		}
+		var page listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("s3 list: %w", err)
+		}
+		for _, object := range page.Contents {
+			if err := fn(object.Key); err != nil {
+				return err
+			}
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+var (
+	errObjectNotFound     = errors.New("s3 object not found")
+	errPreconditionFailed = errors.New("s3 precondition failed")
+)
+
+// do signs and sends the request; non-2xx responses become errors.
+func (r *S3Repository) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	r.signer.sign(req, payloadHash, r.now())
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, errObjectNotFound
+	case http.StatusPreconditionFailed:
+		return nil, errPreconditionFailed
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// objectURL addresses the object name under the configured prefix.
+func (r *S3Repository) objectURL(name string) *url.URL {
+	u := r.bucketURL()
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + r.prefix + name
+	return u
+}
+
+// bucketURL addresses the bucket itself, as ListObjectsV2 expects.
+func (r *S3Repository) bucketURL() *url.URL {
+	u := *r.endpoint
+	if r.pathStyle {
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + r.bucket
+	} else {
+		u.Host = r.bucket + "." + u.Host
+		u.Path = strings.TrimRight(u.Path, "/") + "/"
+	}
+	return &u
+}
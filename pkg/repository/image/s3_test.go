@@ -0,0 +1,303 @@
+package image_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	imagerepo "pod_api/pkg/repository/image"
+
+	"github.com/stretchr/testify/require"
+)
+
+type storedObject struct {
+	data   []byte
+	header http.Header
+}
+
+// fakeStorage is a minimal stand-in for an S3-compatible server: it checks
+// that requests are signed and keeps objects with their metadata headers.
+type fakeStorage struct {
+	*httptest.Server
+	mu      sync.Mutex
+	objects map[string]storedObject
+	// unconditional makes PUT ignore If-None-Match.
+	unconditional bool
+	// ignoreRange makes GET answer 200 with the whole object.
+	ignoreRange bool
+}
+
+// keys returns the stored object paths, sorted.
+func (f *fakeStorage) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// listObjects answers ListObjectsV2 for the bucket at path; f.mu is held.
+func (f *fakeStorage) listObjects(w http.ResponseWriter, bucket string, query url.Values) {
+	var result struct {
+		XMLName  xml.Name `xml:"ListBucketResult"`
+		Contents []struct {
+			Key string `xml:"Key"`
+		} `xml:"Contents"`
+	}
+	for path := range f.objects {
+		key := strings.TrimPrefix(path, bucket+"/")
+		if strings.HasPrefix(key, query.Get("prefix")) {
+			result.Contents = append(result.Contents, struct {
+				Key string `xml:"Key"`
+			}{key})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func fakeS3(t *testing.T) *fakeStorage {
+	t.Helper()
+	f := &fakeStorage{objects: map[string]storedObject{}}
+	mu, objects := &f.mu, f.objects
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signed := strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+			(r.Method == http.MethodGet && r.URL.Query().Get("X-Amz-Signature") != "")
+		if !signed {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			if _, exists := objects[r.URL.Path]; exists && r.Header.Get("If-None-Match") == "*" && !f.unconditional {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			header := http.Header{"Content-Type": r.Header.Values("Content-Type")}
+			for name, values := range r.Header {
+				if strings.HasPrefix(name, "X-Amz-Meta-") {
+					header[name] = values
+				}
+			}
+			objects[r.URL.Path] = storedObject{data: data, header: header}
+		case http.MethodGet, http.MethodHead:
+			if r.URL.Query().Get("list-type") == "2" {
+				f.listObjects(w, r.URL.Path, r.URL.Query())
+				return
+			}
+			object, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			for name, values := range object.header {
+				w.Header()[name] = values
+			}
+			data := object.data
+			if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok && !f.ignoreRange {
+				start, err := strconv.Atoi(strings.TrimSuffix(spec, "-"))
+				require.NoError(t, err)
+				data = data[start:]
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(object.data)-1, len(object.data)))
+				w.WriteHeader(http.StatusPartialContent)
+			}
+			_, _ = w.Write(data)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func newS3Repository(t *testing.T, server *fakeStorage, sweepInterval time.Duration) *imagerepo.S3Repository {
+	t.Helper()
+	opts := imagerepo.NewS3Options()
+	opts.Endpoint = server.URL
+	opts.Bucket = "images"
+	opts.Prefix = "uploads/"
+	opts.AccessKeyID = "key"
+	opts.SecretAccessKey = "secret"
+	opts.SweepInterval = sweepInterval
+	repo, err := imagerepo.NewS3Repository(opts)
+	require.NoError(t, err)
+	t.Cleanup(repo.Close)
+	return repo
+}
+
+func TestS3Repository(t *testing.T) {
+	ctx := context.Background()
+	server := fakeS3(t)
+
+	repo := newS3Repository(t, server, 0)
+
+	kept, err := repo.Save(ctx, []byte("kept"), imagerepo.Metadata{ContentType: "image/jpeg", Filename: "фото 1.jpg"}, time.Hour)
+	require.NoError(t, err)
+	data, meta, ok := repo.GetWithMeta(ctx, kept)
+	require.True(t, ok)
+	require.Equal(t, []byte("kept"), data)
+	require.Equal(t, "image/jpeg", meta.ContentType)
+	require.Equal(t, "фото 1.jpg", meta.Filename)
+
+	stat, ok := repo.Stat(ctx, kept)
+	require.True(t, ok)
+	require.Equal(t, meta.SHA256, stat.SHA256)
+	require.Equal(t, 4, stat.Size)
+	require.True(t, stat.UploadedAt.Equal(meta.UploadedAt))
+
+	link, err := repo.PresignGet(ctx, kept)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(link, server.URL+"/images/uploads/"+kept+"?"), link)
+	resp, err := http.Get(link)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, []byte("kept"), body)
+
+	expiring, err := repo.Save(ctx, []byte("expiring"), imagerepo.Metadata{}, 10*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, ok = repo.Get(ctx, expiring)
+	require.False(t, ok, "expired images are not returned")
+
+	twice, err := repo.Save(ctx, []byte("twice"), imagerepo.Metadata{MaxReads: 2}, time.Hour)
+	require.NoError(t, err)
+	rdr, meta, ok := repo.Open(ctx, twice)
+	require.True(t, ok)
+	require.Zero(t, meta.Reads, "open does not count")
+	require.NoError(t, rdr.Close())
+	for reads := 1; reads <= 2; reads++ {
+		rdr, meta, ok := repo.Read(ctx, twice)
+		require.True(t, ok)
+		require.Equal(t, reads, meta.Reads)
+		// Ranged download from the seek position.
+		_, err = rdr.Seek(2, io.SeekStart)
+		require.NoError(t, err)
+		rest, err := io.ReadAll(rdr)
+		require.NoError(t, err)
+		require.Equal(t, []byte("ice"), rest)
+		require.NoError(t, rdr.Close())
+	}
+	_, _, ok = repo.Read(ctx, twice)
+	require.False(t, ok)
+	for _, key := range server.keys() {
+		require.NotContains(t, key, twice, "markers are removed with the image")
+	}
+
+	require.NoError(t, repo.Delete(ctx, kept))
+	_, ok = repo.Get(ctx, kept)
+	require.False(t, ok)
+
+	_, ok = repo.Get(ctx, "../other-bucket")
+	require.False(t, ok)
+}
+
+func TestS3RepositoryRangeIgnored(t *testing.T) {
+	ctx := context.Background()
+	server := fakeS3(t)
+	server.ignoreRange = true
+	repo := newS3Repository(t, server, 0)
+
+	id, err := repo.Save(ctx, []byte("image"), imagerepo.Metadata{}, time.Hour)
+	require.NoError(t, err)
+	rdr, _, ok := repo.Open(ctx, id)
+	require.True(t, ok)
+	defer rdr.Close()
+	_, err = rdr.Seek(2, io.SeekStart)
+	require.NoError(t, err)
+	rest, err := io.ReadAll(rdr)
+	require.NoError(t, err)
+	require.Equal(t, []byte("age"), rest, "the head of a full response is skipped")
+}
+
+func TestS3RepositorySweep(t *testing.T) {
+	ctx := context.Background()
+	server := fakeS3(t)
+	repo := newS3Repository(t, server, 5*time.Millisecond)
+
+	removed := make(chan string, 1)
+	repo.OnRemove(func(_ context.Context, id string, meta imagerepo.Metadata, reason imagerepo.RemoveReason) {
+		require.Equal(t, imagerepo.RemovedExpired, reason)
+		require.Equal(t, 8, meta.Size)
+		removed <- id
+	})
+
+	kept, err := repo.Save(ctx, []byte("kept"), imagerepo.Metadata{}, time.Hour)
+	require.NoError(t, err)
+	expiring, err := repo.Save(ctx, []byte("expiring"), imagerepo.Metadata{}, 20*time.Millisecond)
+	require.NoError(t, err)
+	// A read marker whose image is gone, e.g. after a failed cleanup.
+	server.mu.Lock()
+	server.objects["/images/uploads/0b8e3c52-5d7e-4a43-9a43-2f1ad0a2a6e1.reads/1"] = storedObject{}
+	server.mu.Unlock()
+
+	// Nobody reads the expiring image, as with presigned links.
+	require.Eventually(t, func() bool {
+		return len(server.keys()) == 1
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, []string{"/images/uploads/" + kept}, server.keys())
+	require.Equal(t, expiring, <-removed)
+}
+
+func TestS3RepositoryPresignTTL(t *testing.T) {
+	ctx := context.Background()
+	server := fakeS3(t)
+	repo := newS3Repository(t, server, 0)
+
+	for _, tt := range []struct {
+		ttl     time.Duration
+		expires int
+	}{
+		{time.Hour, 300},
+		{90 * time.Second, 89},
+	} {
+		id, err := repo.Save(ctx, []byte("image"), imagerepo.Metadata{}, tt.ttl)
+		require.NoError(t, err)
+		link, err := repo.PresignGet(ctx, id)
+		require.NoError(t, err)
+		u, err := url.Parse(link)
+		require.NoError(t, err)
+		expires, err := strconv.Atoi(u.Query().Get("X-Amz-Expires"))
+		require.NoError(t, err)
+		require.InDelta(t, tt.expires, expires, 1, tt.ttl)
+	}
+
+	id, err := repo.Save(ctx, []byte("image"), imagerepo.Metadata{}, 500*time.Millisecond)
+	require.NoError(t, err)
+	_, err = repo.PresignGet(ctx, id)
+	require.Error(t, err, "links never outlive the image")
+}
+
+func TestS3RepositoryNeedsConditionalWrites(t *testing.T) {
+	server := fakeS3(t)
+	server.unconditional = true
+
+	opts := imagerepo.NewS3Options()
+	opts.Endpoint = server.URL
+	opts.Bucket = "images"
+	opts.AccessKeyID = "key"
+	opts.SecretAccessKey = "secret"
+	_, err := imagerepo.NewS3Repository(opts)
+	require.ErrorContains(t, err, "If-None-Match")
+	require.Empty(t, server.keys(), "the probe is removed")
+}
@@ -0,0 +1,111 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/metrics"
+)
+
+type jobEntry struct {
+	job   Job
+	ttl   time.Duration
+	timer *time.Timer
+}
+
+// MemoryRepository is an in-memory JobRepository implementation.
+type MemoryRepository struct {
+	mu   sync.RWMutex
+	data map[string]*jobEntry
+	reg  *metrics.Registry
+	now  func() time.Time
+}
+
+// NewMemoryRepository creates an empty in-memory repository.
+func NewMemoryRepository(reg *metrics.Registry) *MemoryRepository {
+	return &MemoryRepository{
+		data: make(map[string]*jobEntry),
+		reg:  reg,
+		now:  time.Now,
+	}
+}
+
+// Create stores a new job with TTL-based auto-deletion.
+func (r *MemoryRepository) Create(ctx context.Context, job Job, ttl time.Duration) (Job, error) {
+	now := r.now().UTC()
+	job.ID = uuid.NewString()
+	job.CreatedAt, job.UpdatedAt = now, now
+	job.ExpiresAt = time.Time{}
+	if ttl > 0 {
+		job.ExpiresAt = now.Add(ttl)
+	}
+
+	entry := &jobEntry{job: job, ttl: ttl}
+	r.mu.Lock()
+	if ttl > 0 {
+		id := job.ID
+		entry.timer = time.AfterFunc(ttl, func() {
+			r.expire(id)
+		})
+	}
+	r.data[job.ID] = entry
+	r.mu.Unlock()
+
+	log.Ctx(ctx).Info().Str("job_id", job.ID).Msg("job created")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "jobs_created_total", map[string]string{}, 1)
+	}
+	return job, nil
+}
+
+// Get returns the stored job.
+func (r *MemoryRepository) Get(ctx context.Context, id string) (Job, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.data[id]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// Update replaces the job and resets its TTL timer.
+func (r *MemoryRepository) Update(ctx context.Context, job Job) (Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.data[job.ID]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	now := r.now().UTC()
+	job.CreatedAt = e.job.CreatedAt
+	job.UpdatedAt = now
+	job.ExpiresAt = time.Time{}
+	if e.timer != nil {
+		e.timer.Reset(e.ttl)
+		job.ExpiresAt = now.Add(e.ttl)
+	}
+	e.job = job
+	return job, nil
+}
+
+// expire removes a job whose TTL has passed.
+func (r *MemoryRepository) expire(id string) {
+	r.mu.Lock()
+	e, ok := r.data[id]
+	if ok {
+		delete(r.data, id)
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	log.Info().Str("job_id", id).Str("status", string(e.job.Status)).Msg("job expired")
+	if r.reg != nil {
+		r.reg.Inc(context.Background(), "jobs_expired_total", map[string]string{}, 1)
+	}
+}
@@ -0,0 +1,54 @@
+package job_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"pod_api/pkg/repository/job"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepositoryUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := job.NewMemoryRepository(nil)
+
+	created, err := repo.Create(ctx, job.Job{Status: job.StatusQueued, Callback: "https://hooks.example.com/x"}, time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, created.ID)
+	require.False(t, created.Done())
+	require.Equal(t, created.CreatedAt.Add(time.Minute), created.ExpiresAt)
+
+	created.Status = job.StatusSucceeded
+	created.Result = json.RawMessage(`{"ok":true}`)
+	updated, err := repo.Update(ctx, created)
+	require.NoError(t, err)
+	require.True(t, updated.Done())
+
+	stored, ok := repo.Get(ctx, created.ID)
+	require.True(t, ok)
+	require.Equal(t, job.StatusSucceeded, stored.Status)
+	require.JSONEq(t, `{"ok":true}`, string(stored.Result))
+	require.Equal(t, "https://hooks.example.com/x", stored.Callback)
+	require.Equal(t, created.CreatedAt, stored.CreatedAt)
+
+	_, err = repo.Update(ctx, job.Job{ID: "missing"})
+	require.ErrorIs(t, err, job.ErrNotFound)
+}
+
+func TestMemoryRepositoryTTL(t *testing.T) {
+	ctx := context.Background()
+	repo := job.NewMemoryRepository(nil)
+
+	created, err := repo.Create(ctx, job.Job{Status: job.StatusQueued}, 20*time.Millisecond)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, ok := repo.Get(ctx, created.ID)
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+	_, err = repo.Update(ctx, created)
+	require.ErrorIs(t, err, job.ErrNotFound)
+}
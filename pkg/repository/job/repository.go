@@ -0,0 +1,58 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a job does not exist or has expired.
+var ErrNotFound = errors.New("job not found")
+
+// Status is the state of a job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Job is the stored state of an asynchronous request. Results are kept as
+// JSON so that stores do not depend on API types.
+type Job struct {
+	ID     string
+	Status Status
+	// Result is the response body of a succeeded job.
+	Result json.RawMessage
+	// Error is the error body of a failed job and ErrorStatus the HTTP
+	// status the synchronous endpoint would have answered with.
+	Error       json.RawMessage
+	ErrorStatus int
+	// Callback is notified when the job finishes.
+	Callback  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// ExpiresAt is when the job is removed unless updated again.
+	ExpiresAt time.Time
+}
+
+// Done reports whether the job has finished.
+func (j Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// JobRepository stores jobs of asynchronous requests. Jobs expire after
+// TTL without updates.
+type JobRepository interface {
+	// Create stores a new job and returns it with the UUID identifier and
+	// timestamps filled in. ttl defines how long the job lives without updates.
+	Create(ctx context.Context, job Job, ttl time.Duration) (Job, error)
+	// Get returns the job. The boolean indicates presence.
+	Get(ctx context.Context, id string) (Job, bool)
+	// Update replaces the stored job and prolongs its TTL; ErrNotFound is
+	// returned for expired jobs.
+	Update(ctx context.Context, job Job) (Job, error)
+}
@@ -0,0 +1,132 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/metrics"
+	"pod_api/pkg/models"
+)
+
+type sessionEntry struct {
+	messages []models.ChatMessage
+	ttl      time.Duration
+	timer    *time.Timer
+}
+
+// MemoryRepository is an in-memory SessionRepository implementation.
+type MemoryRepository struct {
+	mu          sync.RWMutex
+	data        map[string]*sessionEntry
+	reg         *metrics.Registry
+	maxMessages int
+}
+
+// NewMemoryRepository creates an empty in-memory repository.
+// maxMessages caps stored history per session (0 means unlimited);
+// the oldest messages are dropped first.
+func NewMemoryRepository(reg *metrics.Registry, maxMessages int) *MemoryRepository {
+	return &MemoryRepository{
+		data:        make(map[string]*sessionEntry),
+		reg:         reg,
+		maxMessages: maxMessages,
+	}
+}
+
+// Create starts an empty session with TTL-based auto-deletion.
+func (r *MemoryRepository) Create(ctx context.Context, ttl time.Duration) (string, error) {
+	id := uuid.NewString()
+
+	entry := &sessionEntry{ttl: ttl}
+	if ttl > 0 {
+		entry.timer = time.AfterFunc(ttl, func() {
+			// Background deletion; context not required.
+			_ = r.Delete(context.Background(), id)
+		})
+	}
+
+	r.mu.Lock()
+	r.data[id] = entry
+	r.mu.Unlock()
+
+	log.Ctx(ctx).Info().Str("session_id", id).Msg("session created")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "sessions_created_total", map[string]string{}, 1)
+	}
+
+	return id, nil
+}
+
+// Get returns a copy of the session history.
+func (r *MemoryRepository) Get(ctx context.Context, id string) ([]models.ChatMessage, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.data[id]
+	if !ok || e == nil {
+		return nil, false
+	}
+	out := make([]models.ChatMessage, len(e.messages))
+	copy(out, e.messages)
+	return out, true
+}
+
+// Append adds messages, trims history to maxMessages and resets the TTL timer.
+func (r *MemoryRepository) Append(ctx context.Context, id string, messages ...models.ChatMessage) error {
+	r.mu.Lock()
+	e, ok := r.data[id]
+	if !ok || e == nil {
+		r.mu.Unlock()
+		return ErrNotFound
+	}
+	e.messages = append(e.messages, messages...)
+	if r.maxMessages > 0 && len(e.messages) > r.maxMessages {
+		e.messages = trimHistory(e.messages, r.maxMessages)
+	}
+	if e.timer != nil {
+		e.timer.Reset(e.ttl)
+	}
+	r.mu.Unlock()
+
+	if r.reg != nil {
+		r.reg.Inc(ctx, "session_messages_total", map[string]string{}, int64(len(messages)))
+	}
+	return nil
+}
+
+// Delete stops the TTL timer and removes the session from memory.
+func (r *MemoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	e, ok := r.data[id]
+	if ok {
+		delete(r.data, id)
+	}
+	r.mu.Unlock()
+
+	if !ok || e == nil {
+		return ErrNotFound
+	}
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+
+	log.Ctx(ctx).Info().Str("session_id", id).Int("messages", len(e.messages)).Msg("session deleted")
+	if r.reg != nil {
+		r.reg.Inc(ctx, "sessions_deleted_total", map[string]string{}, 1)
+	}
+	return nil
+}
+
+// trimHistory keeps at most limit latest messages and makes sure
+// the history does not start with an assistant reply.
+func trimHistory(messages []models.ChatMessage, limit int) []models.ChatMessage {
+	start := len(messages) - limit
+	for start < len(messages) && messages[start].Role == models.RoleAssistant {
+		start++
+	}
+	out := make([]models.ChatMessage, len(messages)-start)
+	copy(out, messages[start:])
+	return out
+}
@@ -0,0 +1,54 @@
+package session_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pod_api/pkg/models"
+	"pod_api/pkg/repository/session"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepositoryHistory(t *testing.T) {
+	ctx := context.Background()
+	repo := session.NewMemoryRepository(nil, 3)
+
+	id, err := repo.Create(ctx, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Append(ctx, id,
+		models.ChatMessage{Role: models.RoleUser, Content: "q1"},
+		models.ChatMessage{Role: models.RoleAssistant, Content: "a1"},
+	))
+	require.NoError(t, repo.Append(ctx, id,
+		models.ChatMessage{Role: models.RoleUser, Content: "q2"},
+		models.ChatMessage{Role: models.RoleAssistant, Content: "a2"},
+	))
+
+	// Trimmed to the last turn: a dangling assistant reply is dropped too.
+	history, ok := repo.Get(ctx, id)
+	require.True(t, ok)
+	require.Len(t, history, 2)
+	require.Equal(t, "q2", history[0].Content)
+
+	require.NoError(t, repo.Delete(ctx, id))
+	_, ok = repo.Get(ctx, id)
+	require.False(t, ok)
+	require.ErrorIs(t, repo.Delete(ctx, id), session.ErrNotFound)
+}
+
+func TestMemoryRepositoryTTL(t *testing.T) {
+	ctx := context.Background()
+	repo := session.NewMemoryRepository(nil, 0)
+
+	id, err := repo.Create(ctx, 20*time.Millisecond)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, ok := repo.Get(ctx, id)
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+	require.ErrorIs(t, repo.Append(ctx, id, models.ChatMessage{Role: models.RoleUser, Content: "late"}), session.ErrNotFound)
+}
@@ -0,0 +1,26 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pod_api/pkg/models"
+)
+
+// ErrNotFound is returned when a session does not exist or has expired.
+var ErrNotFound = errors.New("session not found")
+
+// SessionRepository stores chat history of multi-turn conversations.
+// Sessions expire after TTL of inactivity.
+type SessionRepository interface {
+	// Create starts an empty session and returns its UUID identifier.
+	// ttl defines how long the session lives without new messages.
+	Create(ctx context.Context, ttl time.Duration) (string, error)
+	// Get returns a copy of the session history. The boolean indicates presence.
+	Get(ctx context.Context, id string) ([]models.ChatMessage, bool)
+	// Append adds messages to the history and prolongs the session TTL.
+	Append(ctx context.Context, id string, messages ...models.ChatMessage) error
+	// Delete removes the session with its history.
+	Delete(ctx context.Context, id string) error
+}
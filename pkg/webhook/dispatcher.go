@@ -0,0 +1,472 @@
+// Package webhook delivers signed webhook requests with retries from a
+// bounded worker pool and keeps a log of recent deliveries.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"pod_api/pkg/metrics"
+)
+
+// Headers of every delivery. The signature is "sha256=" followed by the hex
+// HMAC-SHA256 of "<timestamp>.<body>", see Sign.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Status is the state of a delivery.
+type Status string
+
+const (
+	// StatusPending deliveries are queued or waiting for a retry.
+	StatusPending Status = "pending"
+	// StatusDelivered deliveries got a 2xx answer.
+	StatusDelivered Status = "delivered"
+	// StatusFailed deliveries ran out of attempts or were rejected.
+	StatusFailed Status = "failed"
+)
+
+var (
+	// ErrDestinationNotAllowed is returned for URLs outside AllowedHosts
+	// and recorded for deliveries refused at an internal address.
+	ErrDestinationNotAllowed = errors.New("webhook destination is not allowed")
+	// ErrQueueFull is returned when the delivery queue has no room.
+	ErrQueueFull = errors.New("webhook queue is full")
+)
+
+// Options controls optional parameters for NewDispatcher.
+type Options struct {
+	// Secret signs deliveries; empty sends them unsigned.
+	Secret []byte
+
+	// AllowedHosts lists destination hosts; "*.example.com" matches
+	// subdomains. Nothing is allowed when empty.
+	AllowedHosts []string
+
+	// Workers bounds concurrent deliveries.
+	Workers int
+	// QueueSize bounds deliveries waiting for a worker.
+	QueueSize int
+
+	// MaxAttempts limits attempts per delivery, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; every next retry
+	// waits twice as long, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Timeout bounds a single attempt.
+	Timeout time.Duration
+
+	// LogSize is how many recent deliveries are kept for inspection.
+	LogSize int
+
+	// AllowPrivateNetworks lets deliveries reach loopback, private and
+	// link-local addresses. By default such addresses are refused when the
+	// connection is dialed, so an allowed host name that resolves inside
+	// the network is not reachable either.
+	AllowPrivateNetworks bool
+
+	// HTTPClient is optional; redirects are never followed. Its transport
+	// is cloned to guard dialing unless AllowPrivateNetworks is set; other
+	// round trippers are used as is.
+	HTTPClient *http.Client
+
+	// Metrics is optional; nil disables webhook metrics.
+	Metrics *metrics.Registry
+}
+
+// NewOptions returns sensible defaults.
+func NewOptions() Options {
+	return Options{
+		Workers:        4,
+		QueueSize:      256,
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Timeout:        5 * time.Second,
+		LogSize:        1000,
+	}
+}
+
+// Delivery is a log record of a webhook.
+type Delivery struct {
+	ID    string
+	Event string
+	URL   string
+	// SubscriptionID is set for events published to a subscription.
+	SubscriptionID string
+	Status         Status
+	Attempts       int
+	// LastStatusCode is the HTTP status of the last attempt, 0 if the
+	// request failed before an answer.
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	// NextAttemptAt is set while a retry is scheduled.
+	NextAttemptAt time.Time
+}
+
+// job is a queued delivery attempt.
+type job struct {
+	id    string
+	event string
+	url   string
+	body  []byte
+	// secret signs the request; empty sends it unsigned.
+	secret []byte
+	// attempts made so far; the log entry may be evicted meanwhile.
+	attempts int
+}
+
+// Dispatcher sends webhooks from a fixed pool of workers.
+type Dispatcher struct {
+	opts   Options
+	client *http.Client
+	queue  chan job
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+	now    func() time.Time
+
+	mu         sync.Mutex
+	deliveries map[string]*Delivery
+	// retrying counts scheduled retries; it is bounded by QueueSize.
+	retrying int
+	// order holds delivery ids from the oldest; it bounds the log.
+	order []string
+
+	subscriptions map[string]Subscription
+}
+
+// NewDispatcher starts the worker pool.
+func NewDispatcher(opts Options) (*Dispatcher, error) {
+	if opts.Workers <= 0 || opts.QueueSize <= 0 {
+		return nil, errors.New("webhook workers and queue size should be positive")
+	}
+	if opts.MaxAttempts <= 0 {
+		return nil, errors.New("webhook max attempts should be positive")
+	}
+	client := &http.Client{}
+	if opts.HTTPClient != nil {
+		copied := *opts.HTTPClient
+		client = &copied
+	}
+	if !opts.AllowPrivateNetworks {
+		client.Transport = guardTransport(client.Transport)
+	}
+	// A redirect could lead outside AllowedHosts.
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	d := &Dispatcher{
+		opts:       opts,
+		client:     client,
+		queue:      make(chan job, opts.QueueSize),
+		stopCh:     make(chan struct{}),
+		now:        time.Now,
+		deliveries: map[string]*Delivery{},
+
+		subscriptions: map[string]Subscription{},
+	}
+	for range opts.Workers {
+		d.wg.Add(1)
+		go d.worker()
+	}
+	return d, nil
+}
+
+// Close stops the workers; queued deliveries and scheduled retries are
+// dropped.
+func (d *Dispatcher) Close() {
+	close(d.stopCh)
+	d.wg.Wait()
+}
+
+// Check reports whether rawURL is an allowed destination.
+func (d *Dispatcher) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil {
+		return ErrDestinationNotAllowed
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range d.opts.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok && suffix != "" {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return nil
+			}
+		} else if host == allowed {
+			return nil
+		}
+	}
+	return ErrDestinationNotAllowed
+}
+
+// Send queues a delivery of body to rawURL signed with Options.Secret and
+// returns its log record.
+func (d *Dispatcher) Send(ctx context.Context, rawURL, event string, body []byte) (Delivery, error) {
+	if err := d.Check(rawURL); err != nil {
+		return Delivery{}, err
+	}
+	return d.enqueue(ctx, job{event: event, url: rawURL, body: body, secret: d.opts.Secret}, "")
+}
+
+// enqueue records a delivery of j and queues its first attempt.
+func (d *Dispatcher) enqueue(ctx context.Context, j job, subscriptionID string) (Delivery, error) {
+	now := d.now()
+	delivery := &Delivery{
+		ID:             uuid.NewString(),
+		Event:          j.event,
+		URL:            j.url,
+		SubscriptionID: subscriptionID,
+		Status:         StatusPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	j.id = delivery.ID
+	d.mu.Lock()
+	d.record(delivery)
+	d.mu.Unlock()
+
+	select {
+	case d.queue <- j:
+	default:
+		d.finish(ctx, delivery.ID, 0, ErrQueueFull)
+		return d.snapshot(delivery.ID), ErrQueueFull
+	}
+	return d.snapshot(delivery.ID), nil
+}
+
+// List returns recent deliveries, newest first, optionally filtered by
+// status.
+func (d *Dispatcher) List(status Status, limit int) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := []Delivery{}
+	for i := len(d.order) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		delivery := d.deliveries[d.order[i]]
+		if status == "" || delivery.Status == status {
+			out = append(out, *delivery)
+		}
+	}
+	return out
+}
+
+// Get returns a delivery from the log.
+func (d *Dispatcher) Get(id string) (Delivery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delivery, ok := d.deliveries[id]
+	if !ok {
+		return Delivery{}, false
+	}
+	return *delivery, true
+}
+
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case j := <-d.queue:
+			d.attempt(j)
+		}
+	}
+}
+
+// attempt sends j once and either finishes the delivery or schedules a
+// retry.
+func (d *Dispatcher) attempt(j job) {
+	ctx := context.Background()
+	code, err := d.post(ctx, j)
+	j.attempts++
+
+	d.mu.Lock()
+	delivery, ok := d.deliveries[j.id]
+	if !ok {
+		// Evicted from the log.
+		delivery = &Delivery{ID: j.id}
+	}
+	delivery.Attempts = j.attempts
+	d.mu.Unlock()
+
+	if err == nil || !retryable(code, err) || j.attempts >= d.opts.MaxAttempts {
+		d.finish(ctx, j.id, code, err)
+		return
+	}
+
+	wait := d.backoff(j.attempts)
+	d.mu.Lock()
+	if d.retrying >= d.opts.QueueSize {
+		d.mu.Unlock()
+		d.finish(ctx, j.id, code, fmt.Errorf("retry dropped: %w; last error: %v", ErrQueueFull, err))
+		return
+	}
+	d.retrying++
+	delivery.LastStatusCode, delivery.LastError = code, err.Error()
+	delivery.UpdatedAt = d.now()
+	delivery.NextAttemptAt = delivery.UpdatedAt.Add(wait)
+	d.mu.Unlock()
+	d.inc(ctx, "webhook_attempts_total", map[string]string{"outcome": "retry"})
+	log.Warn().Err(err).Str("delivery_id", j.id).Str("event", j.event).Int("attempt", j.attempts).Dur("retry_in", wait).Msg("webhook delivery failed, retrying")
+
+	time.AfterFunc(wait, func() { d.retry(j) })
+}
+
+// retry queues a scheduled attempt of j. It never blocks: when the queue
+// is full the delivery fails instead of holding a goroutine.
+func (d *Dispatcher) retry(j job) {
+	d.mu.Lock()
+	d.retrying--
+	d.mu.Unlock()
+	select {
+	case <-d.stopCh:
+		return
+	default:
+	}
+	select {
+	case d.queue <- j:
+	default:
+		d.finish(context.Background(), j.id, 0, fmt.Errorf("retry dropped: %w", ErrQueueFull))
+	}
+}
+
+// post makes a single signed request and returns its status.
+func (d *Dispatcher) post(ctx context.Context, j job) (int, error) {
+	if d.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.opts.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.url, bytes.NewReader(j.body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, j.id)
+	req.Header.Set(HeaderEvent, j.event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if len(j.secret) != 0 {
+		req.Header.Set(HeaderSignature, Sign(j.secret, timestamp, j.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// finish records the final outcome of a delivery.
+func (d *Dispatcher) finish(ctx context.Context, id string, code int, err error) {
+	d.mu.Lock()
+	delivery, ok := d.deliveries[id]
+	if ok {
+		delivery.LastStatusCode = code
+		delivery.UpdatedAt = d.now()
+		delivery.NextAttemptAt = time.Time{}
+		delivery.Status = StatusDelivered
+		delivery.LastError = ""
+		if err != nil {
+			delivery.Status = StatusFailed
+			delivery.LastError = err.Error()
+		}
+	}
+	d.mu.Unlock()
+
+	if err != nil {
+		d.inc(ctx, "webhook_attempts_total", map[string]string{"outcome": "failure"})
+		d.inc(ctx, "webhook_deliveries_total", map[string]string{"status": string(StatusFailed)})
+		log.Error().Err(err).Str("delivery_id", id).Msg("webhook delivery failed")
+		return
+	}
+	d.inc(ctx, "webhook_attempts_total", map[string]string{"outcome": "success"})
+	d.inc(ctx, "webhook_deliveries_total", map[string]string{"status": string(StatusDelivered)})
+}
+
+// record adds a delivery to the log, evicting the oldest ones over LogSize.
+// Callers hold d.mu.
+func (d *Dispatcher) record(delivery *Delivery) {
+	d.deliveries[delivery.ID] = delivery
+	d.order = append(d.order, delivery.ID)
+	for d.opts.LogSize > 0 && len(d.order) > d.opts.LogSize {
+		delete(d.deliveries, d.order[0])
+		d.order = d.order[1:]
+	}
+}
+
+func (d *Dispatcher) snapshot(id string) Delivery {
+	delivery, _ := d.Get(id)
+	return delivery
+}
+
+// backoff returns the delay before retry n (1-based) with jitter in
+// [delay/2, delay].
+func (d *Dispatcher) backoff(n int) time.Duration {
+	wait := d.opts.InitialBackoff
+	for i := 1; i < n && wait < d.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if d.opts.MaxBackoff > 0 && wait > d.opts.MaxBackoff {
+		wait = d.opts.MaxBackoff
+	}
+	if wait <= 1 {
+		return wait
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func (d *Dispatcher) inc(ctx context.Context, name string, labels map[string]string) {
+	if d.opts.Metrics != nil {
+		d.opts.Metrics.Inc(ctx, name, labels, 1)
+	}
+}
+
+// retryable reports whether a failed attempt may succeed later: network
+// errors, timeouts, 429 and 5xx are retried, other answers and refused
+// destinations are final.
+func retryable(code int, err error) bool {
+	if errors.Is(err, ErrQueueFull) || errors.Is(err, ErrDestinationNotAllowed) {
+		return false
+	}
+	return code == 0 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp.
+// Receivers recompute it with the shared secret and reject stale
+// timestamps to prevent replays.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
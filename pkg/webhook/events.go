@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Event types published to subscriptions.
+const (
+	EventImageSaved        = "image.saved"
+	EventImageFetched      = "image.fetched"
+	EventImageExpired      = "image.expired"
+	EventImageDeleted      = "image.deleted"
+	EventAnalysisCompleted = "analysis.completed"
+)
+
+// EventTypes lists the event types a subscription may select.
+var EventTypes = []string{
+	EventImageSaved,
+	EventImageFetched,
+	EventImageExpired,
+	EventImageDeleted,
+	EventAnalysisCompleted,
+}
+
+// EventVersion is the version of the Event envelope. Fields are only added
+// within a version; renaming or removing one bumps it.
+const EventVersion = "1"
+
+// ErrUnknownEvent is returned by Subscribe for event types outside
+// EventTypes.
+var ErrUnknownEvent = errors.New("unknown webhook event type")
+
+// Event is the JSON envelope of published events; Data depends on Type.
+type Event struct {
+	Version   string    `json:"version"`
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// Subscription receives published events of the selected types.
+type Subscription struct {
+	ID  string
+	URL string
+	// Secret signs deliveries of the subscription.
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// Subscribe registers rawURL for events. An empty secret is replaced with
+// a random one, returned in the subscription.
+func (d *Dispatcher) Subscribe(rawURL, secret string, events []string) (Subscription, error) {
+	if err := d.Check(rawURL); err != nil {
+		return Subscription{}, err
+	}
+	if len(events) == 0 {
+		return Subscription{}, ErrUnknownEvent
+	}
+	for _, event := range events {
+		if !slices.Contains(EventTypes, event) {
+			return Subscription{}, ErrUnknownEvent
+		}
+	}
+	if secret == "" {
+		buf := make([]byte, 32)
+		_, _ = rand.Read(buf)
+		secret = hex.EncodeToString(buf)
+	}
+
+	sub := Subscription{
+		ID:        uuid.NewString(),
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    slices.Compact(slices.Sorted(slices.Values(events))),
+		CreatedAt: d.now(),
+	}
+	d.mu.Lock()
+	d.subscriptions[sub.ID] = sub
+	d.mu.Unlock()
+	return sub, nil
+}
+
+// Unsubscribe removes a subscription and reports whether it existed.
+func (d *Dispatcher) Unsubscribe(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.subscriptions[id]
+	delete(d.subscriptions, id)
+	return ok
+}
+
+// Subscriptions returns all subscriptions, oldest first.
+func (d *Dispatcher) Subscriptions() []Subscription {
+	d.mu.Lock()
+	out := make([]Subscription, 0, len(d.subscriptions))
+	for _, sub := range d.subscriptions {
+		out = append(out, sub)
+	}
+	d.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+// Subscribed reports whether any subscription receives eventType.
+func (d *Dispatcher) Subscribed(eventType string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, sub := range d.subscriptions {
+		if slices.Contains(sub.Events, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish wraps data into an Event and queues it to every subscription of
+// eventType. All deliveries of an event share its id.
+func (d *Dispatcher) Publish(ctx context.Context, eventType string, data any) {
+	var targets []Subscription
+	for _, sub := range d.Subscriptions() {
+		if slices.Contains(sub.Events, eventType) {
+			targets = append(targets, sub)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	event := Event{Version: EventVersion, ID: uuid.NewString(), Type: eventType, CreatedAt: d.now().UTC(), Data: data}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("event", eventType).Msg("webhook event not encoded")
+		return
+	}
+	for _, sub := range targets {
+		j := job{event: eventType, url: sub.URL, body: body, secret: []byte(sub.Secret)}
+		if _, err := d.enqueue(ctx, j, sub.ID); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("subscription_id", sub.ID).Str("event", eventType).Msg("webhook event not queued")
+		}
+	}
+}
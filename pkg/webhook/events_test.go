@@ -0,0 +1,72 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pod_api/pkg/webhook"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcherPublish(t *testing.T) {
+	received := make(chan webhook.Event, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		secret := r.URL.Query().Get("secret")
+		require.Equal(t, webhook.Sign([]byte(secret), r.Header.Get(webhook.HeaderTimestamp), body), r.Header.Get(webhook.HeaderSignature))
+		var event webhook.Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		received <- event
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	d := newDispatcher(t, "127.0.0.1")
+
+	_, err := d.Subscribe(server.URL+"?secret=one", "one", []string{webhook.EventImageSaved, webhook.EventImageDeleted})
+	require.NoError(t, err)
+	generated, err := d.Subscribe(server.URL, "", []string{webhook.EventImageSaved})
+	require.NoError(t, err)
+	require.Len(t, generated.Secret, 64)
+	_, err = d.Subscribe(server.URL+"?secret="+generated.Secret, generated.Secret, []string{webhook.EventImageExpired})
+	require.NoError(t, err)
+	require.True(t, d.Unsubscribe(generated.ID))
+	require.False(t, d.Subscribed(webhook.EventAnalysisCompleted))
+
+	d.Publish(context.Background(), webhook.EventImageSaved, map[string]string{"id": "a"})
+	d.Publish(context.Background(), webhook.EventAnalysisCompleted, map[string]string{"id": "b"})
+
+	select {
+	case event := <-received:
+		require.Equal(t, webhook.EventVersion, event.Version)
+		require.Equal(t, webhook.EventImageSaved, event.Type)
+		require.NotEmpty(t, event.ID)
+		require.Equal(t, map[string]any{"id": "a"}, event.Data)
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+	select {
+	case event := <-received:
+		t.Fatalf("unexpected event %s", event.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.Len(t, d.Subscriptions(), 2)
+}
+
+func TestDispatcherSubscribeRejects(t *testing.T) {
+	d := newDispatcher(t, "hooks.example.com")
+
+	_, err := d.Subscribe("https://evil.example.net/", "", []string{webhook.EventImageSaved})
+	require.ErrorIs(t, err, webhook.ErrDestinationNotAllowed)
+	_, err = d.Subscribe("https://hooks.example.com/", "", []string{"image.viewed"})
+	require.ErrorIs(t, err, webhook.ErrUnknownEvent)
+	_, err = d.Subscribe("https://hooks.example.com/", "", nil)
+	require.ErrorIs(t, err, webhook.ErrUnknownEvent)
+	require.Empty(t, d.Subscriptions())
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// guardTransport returns a copy of rt that refuses to connect to internal
+// addresses. The check runs on the resolved address at dial time, so DNS
+// answers pointing inside the network are caught too. Deliveries go
+// directly to the destination, never through an environment proxy whose
+// address would be checked instead.
+func guardTransport(rt http.RoundTripper) http.RoundTripper {
+	var transport *http.Transport
+	switch base := rt.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = base.Clone()
+	default:
+		return rt
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDialAddress,
+	}
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	transport.DialTLSContext = nil
+	return transport
+}
+
+// checkDialAddress is a net.Dialer Control hook rejecting internal
+// addresses.
+func checkDialAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDestinationNotAllowed, err)
+	}
+	if !publicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s is an internal address", ErrDestinationNotAllowed, addrPort.Addr())
+	}
+	return nil
+}
+
+// publicAddr reports whether addr is routable on the internet: loopback,
+// private, link-local, shared, unspecified and multicast addresses are not.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}